@@ -4,9 +4,11 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/config"
-	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/repository"
 	"github.com/wafi04/otomaxv2/internal/routes"
 	"github.com/wafi04/otomaxv2/pkg/logger"
+	"github.com/wafi04/otomaxv2/pkg/token"
 )
 
 func main() {
@@ -22,8 +24,18 @@ func main() {
 	}
 	defer db.Close()
 
+	redisConn := config.NewRedisConnection(&cfg.Redis)
+	redisConn.Client = redisConn.Connect()
+	if redisConn.Client == nil {
+		log.Logger.Warn("Redis unavailable, features that need it are disabled")
+	}
+
 	// Setup Gin router
 	r := gin.Default()
+	// client IPs feed the reseller whitelist, so only listed proxies may forward them
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies:", err)
+	}
 
 	// CORS configuration
 	config := cors.DefaultConfig()
@@ -33,11 +45,19 @@ func main() {
 	r.Use(cors.New(config))
 
 	api := r.Group("/api")
-		r.GET("/auth/google/callback", handler.GoogleCallbackHandler)
 
-	routes.ProductExternalRoutes(api, *cfg, db.SqlDB)
+	tokens := token.NewManager(cfg.JWT.SecretKey, cfg.JWT.Issuer, cfg.JWT.ExpireDuration, cfg.JWT.RefreshDuration)
+	authMiddleware := middleware.NewAuthMiddleware(tokens, repository.NewAuthRepository(db.SqlDB))
+
+	routes.AuthRoutes(api, *cfg, db.SqlDB, redisConn.Client, tokens, authMiddleware)
+	routes.ProductExternalRoutes(api, *cfg, db.SqlDB, authMiddleware)
+	routes.TransactionRoutes(api, *cfg, db.SqlDB, redisConn.Client, authMiddleware)
+	routes.DepositRoutes(api, *cfg, db.SqlDB, authMiddleware)
+	routes.MarkupRuleRoutes(api, *cfg, db.SqlDB, authMiddleware)
+	routes.SupplierBalanceRoutes(api, *cfg, db.SqlDB, authMiddleware)
+	routes.NicknameRoutes(api, *cfg, db.SqlDB, redisConn.Client)
 
-	routes.SetupAllRoutes(api, db.SqlDB)
+	routes.SetupAllRoutes(api, db.SqlDB, authMiddleware)
 	r.Run(cfg.Server.Host + ":" + cfg.Server.Port)
 
 }
@@ -29,6 +29,30 @@ type Config struct {
 	// Payment Gateway Configuration
 	PaymentGateway PaymentGatewayConfig `mapstructure:"payment_gateway"`
 
+	// Phone verification OTP
+	OTP OTPConfig `mapstructure:"otp"`
+
+	// Selling price markup per user role
+	Pricing PricingConfig `mapstructure:"pricing"`
+
+	// Order routing between providers
+	Order OrderConfig `mapstructure:"order"`
+
+	// Background product sync
+	Sync SyncConfig `mapstructure:"sync"`
+
+	// Game account nickname lookup
+	Nickname NicknameConfig `mapstructure:"nickname"`
+
+	// Host-to-host reseller API
+	Reseller ResellerConfig `mapstructure:"reseller"`
+
+	// Text command gateway
+	Gateway GatewayConfig `mapstructure:"gateway"`
+
+	// Idempotency-Key handling on create endpoints
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+
 	// External API Configuration
 	ExternalAPI ExternalAPIConfig `mapstructure:"external_api"`
 
@@ -46,6 +70,9 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	// TrustedProxies may set X-Forwarded-For; with none, the client IP is the
+	// peer address.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -100,8 +127,10 @@ type MidtransConfig struct {
 }
 
 type DigiflazzConfig struct {
-	DigiUsername string `mapstructure:"digiusername"`
-	DigiKey      string `mapstructure:"digikey"`
+	DigiUsername  string `mapstructure:"digiusername"`
+	DigiKey       string `mapstructure:"digikey"`
+	CallbackURL   string `mapstructure:"callback_url"`
+	WebhookSecret string `mapstructure:"webhook_secret"`
 }
 
 type XenditConfig struct {
@@ -112,8 +141,108 @@ type XenditConfig struct {
 }
 
 type DuitkuConfig struct {
-	DuitkuKey          string `mapstructure:"duitku_key"`
-	DuitkuMerchantCode string `mapstructure:"duitku_merchant_code"`
+	DuitkuKey          string        `mapstructure:"duitku_key"`
+	DuitkuMerchantCode string        `mapstructure:"duitku_merchant_code"`
+	CallbackURL        string        `mapstructure:"callback_url"`
+	OrderCallbackURL   string        `mapstructure:"order_callback_url"`
+	ReturnURL          string        `mapstructure:"return_url"`
+	ReconcileInterval  time.Duration `mapstructure:"reconcile_interval"`
+	ReconcileAfter     time.Duration `mapstructure:"reconcile_after"`
+	// ReconcileMaxDelay caps the delay between checks of a deposit that
+	// Duitku still reports as pending.
+	ReconcileMaxDelay time.Duration `mapstructure:"reconcile_max_delay"`
+}
+
+type OTPConfig struct {
+	Length         int           `mapstructure:"length"`
+	TTL            time.Duration `mapstructure:"ttl"`
+	MaxAttempts    int           `mapstructure:"max_attempts"`
+	ResendCooldown time.Duration `mapstructure:"resend_cooldown"`
+	// SenderURL is the SMS or WhatsApp relay codes are posted to. Codes are
+	// only logged while it is empty, which production refuses.
+	SenderURL     string        `mapstructure:"sender_url"`
+	SenderKey     string        `mapstructure:"sender_key"`
+	SenderTimeout time.Duration `mapstructure:"sender_timeout"`
+}
+
+// PricingConfig holds the markup over provider cost, in percent, for each user role.
+type PricingConfig struct {
+	MemberMarkup   int `mapstructure:"member_markup"`
+	PlatinumMarkup int `mapstructure:"platinum_markup"`
+	AdminMarkup    int `mapstructure:"admin_markup"`
+}
+
+// OrderConfig controls how orders are sent to providers. PurchaseTimeout bounds
+// a single purchase call before the router checks its status and moves on.
+// Orders are refused while the provider's deposit is below their cost plus
+// SupplierBalanceBuffer; balances older than SupplierBalanceMaxAge are
+// re-checked first and all providers are checked every SupplierBalanceInterval.
+// Paid orders still Pending after StatusCheckAfter are polled at the provider
+// every StatusPollInterval with backoff up to StatusCheckMaxBackoff, and
+// escalated to admins after StatusEscalateAfter. Postpaid bill totals stay
+// locked for BillInquiryTTL after the inquiry. An order identical to one placed
+// within DuplicateWindow is refused unless it is marked as a repeat; categories
+// can set their own window.
+type OrderConfig struct {
+	PurchaseTimeout         time.Duration `mapstructure:"purchase_timeout"`
+	SupplierBalanceBuffer   int           `mapstructure:"supplier_balance_buffer"`
+	SupplierBalanceMaxAge   time.Duration `mapstructure:"supplier_balance_max_age"`
+	SupplierBalanceInterval time.Duration `mapstructure:"supplier_balance_interval"`
+	StatusPollInterval      time.Duration `mapstructure:"status_poll_interval"`
+	StatusCheckAfter        time.Duration `mapstructure:"status_check_after"`
+	StatusCheckMaxBackoff   time.Duration `mapstructure:"status_check_max_backoff"`
+	StatusEscalateAfter     time.Duration `mapstructure:"status_escalate_after"`
+	BillInquiryTTL          time.Duration `mapstructure:"bill_inquiry_ttl"`
+	DuplicateWindow         time.Duration `mapstructure:"duplicate_window"`
+}
+
+// SyncConfig schedules the product sync. An Interval of 0 disables the
+// scheduler. Cost price moves above PriceAlertPercent between syncs raise a price alert.
+type SyncConfig struct {
+	Interval          time.Duration `mapstructure:"interval"`
+	PriceAlertPercent float64       `mapstructure:"price_alert_percent"`
+}
+
+// NicknameConfig points at the third-party nickname API, which is skipped when
+// CheckerURL is empty. Found nicknames are cached for CacheTTL and unknown IDs
+// for MissTTL. Each client IP may check RateLimit nicknames per RateWindow;
+// zero turns the limit off.
+type NicknameConfig struct {
+	CheckerURL string        `mapstructure:"checker_url"`
+	CheckerKey string        `mapstructure:"checker_key"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+	CacheTTL   time.Duration `mapstructure:"cache_ttl"`
+	MissTTL    time.Duration `mapstructure:"miss_ttl"`
+	RateLimit  int           `mapstructure:"rate_limit"`
+	RateWindow time.Duration `mapstructure:"rate_window"`
+}
+
+// ResellerConfig controls order result webhooks sent to resellers.
+type ResellerConfig struct {
+	// CallbackTimeout bounds each webhook POST.
+	CallbackTimeout time.Duration `mapstructure:"callback_timeout"`
+	// WebhookRetryInterval is how often failed webhooks are retried; 0 disables it.
+	WebhookRetryInterval time.Duration `mapstructure:"webhook_retry_interval"`
+	// WebhookBackoff is the delay after the first failure, doubled per attempt
+	// up to WebhookMaxBackoff.
+	WebhookBackoff    time.Duration `mapstructure:"webhook_backoff"`
+	WebhookMaxBackoff time.Duration `mapstructure:"webhook_max_backoff"`
+	// WebhookMaxAttempts is how many automatic attempts a webhook gets.
+	WebhookMaxAttempts int `mapstructure:"webhook_max_attempts"`
+}
+
+// GatewayConfig holds the secret chat and SMS relays send with each message.
+// The gateway rejects every message while it is empty. A phone is locked out
+// for PINLockout after PINMaxAttempts wrong PINs.
+type GatewayConfig struct {
+	RelaySecret    string        `mapstructure:"relay_secret"`
+	PINMaxAttempts int           `mapstructure:"pin_max_attempts"`
+	PINLockout     time.Duration `mapstructure:"pin_lockout"`
+}
+
+// IdempotencyConfig sets how long an Idempotency-Key is remembered.
+type IdempotencyConfig struct {
+	KeyTTL time.Duration `mapstructure:"key_ttl"`
 }
 
 type GoPayConfig struct {
@@ -197,16 +326,19 @@ func LoadConfig() (*Config, error) {
 
 	config := &Config{
 		Digiflazz: DigiflazzConfig{
-			DigiUsername: getEnv("DIGIFLAZZ_USERNAME", ""),
-			DigiKey:      getEnv("DIGIFLAZZ_KEY", ""),
+			DigiUsername:  getEnv("DIGIFLAZZ_USERNAME", ""),
+			DigiKey:       getEnv("DIGIFLAZZ_KEY", ""),
+			CallbackURL:   getEnv("DIGIFLAZZ_CALLBACK_URL", ""),
+			WebhookSecret: getEnv("DIGIFLAZZ_WEBHOOK_SECRET", ""),
 		},
 		Server: ServerConfig{
-			Host:         getEnv("SERVER_HOST", "localhost"),
-			Port:         getEnv("SERVER_PORT", "8081"),
-			Mode:         getEnv("SERVER_MODE", "debug"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 30*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
-			IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			Host:           getEnv("SERVER_HOST", "localhost"),
+			Port:           getEnv("SERVER_PORT", "8081"),
+			Mode:           getEnv("SERVER_MODE", "debug"),
+			ReadTimeout:    getDurationEnv("SERVER_READ_TIMEOUT", 30*time.Second),
+			WriteTimeout:   getDurationEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:    getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			TrustedProxies: getListEnv("SERVER_TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -220,7 +352,7 @@ func LoadConfig() (*Config, error) {
 			MaxOpenConns:    getIntEnv("DB_MAX_OPEN_CONNS", 100),
 			ConnMaxLifetime: getDurationEnv("DB_CONN_MAX_LIFETIME", 1*time.Hour),
 			ConnMaxIdleTime: getDurationEnv("DB_CONN_MAX_IDLE_TIME", 10*time.Minute),
-			MigrationPath:   getEnv("DB_MIGRATION_PATH", "internal/infrastructure/database/postgres/migrations"),
+			MigrationPath:   getEnv("DB_MIGRATION_PATH", "migrations"),
 		},
 		Redis: RedisConfig{
 			Host:        getEnv("REDIS_HOST", "localhost"),
@@ -252,6 +384,12 @@ func LoadConfig() (*Config, error) {
 			DuitkuConfig: DuitkuConfig{
 				DuitkuKey:          getEnv("DUITKU_KEY", ""),
 				DuitkuMerchantCode: getEnv("DUITKU_MERCHANT_CODE", ""),
+				CallbackURL:        getEnv("DUITKU_CALLBACK_URL", "http://localhost:8080/api/deposits/callback/duitku"),
+				OrderCallbackURL:   getEnv("DUITKU_ORDER_CALLBACK_URL", "http://localhost:8080/api/transactions/callback/duitku"),
+				ReturnURL:          getEnv("DUITKU_RETURN_URL", "http://localhost:3000"),
+				ReconcileInterval:  getDurationEnv("DUITKU_RECONCILE_INTERVAL", 5*time.Minute),
+				ReconcileAfter:     getDurationEnv("DUITKU_RECONCILE_AFTER", 15*time.Minute),
+				ReconcileMaxDelay:  getDurationEnv("DUITKU_RECONCILE_MAX_DELAY", 6*time.Hour),
 			},
 			Xendit: XenditConfig{
 				SecretKey:     getEnv("XENDIT_SECRET_KEY", ""),
@@ -266,6 +404,60 @@ func LoadConfig() (*Config, error) {
 				CallbackURL: getEnv("GOPAY_CALLBACK_URL", ""),
 			},
 		},
+		OTP: OTPConfig{
+			Length:         getIntEnv("OTP_LENGTH", 6),
+			TTL:            getDurationEnv("OTP_TTL", 5*time.Minute),
+			MaxAttempts:    getIntEnv("OTP_MAX_ATTEMPTS", 5),
+			ResendCooldown: getDurationEnv("OTP_RESEND_COOLDOWN", time.Minute),
+			SenderURL:      getEnv("OTP_SENDER_URL", ""),
+			SenderKey:      getEnv("OTP_SENDER_KEY", ""),
+			SenderTimeout:  getDurationEnv("OTP_SENDER_TIMEOUT", 10*time.Second),
+		},
+		Pricing: PricingConfig{
+			MemberMarkup:   getIntEnv("PRICE_MARKUP_MEMBER", 15),
+			PlatinumMarkup: getIntEnv("PRICE_MARKUP_PLATINUM", 10),
+			AdminMarkup:    getIntEnv("PRICE_MARKUP_ADMIN", 0),
+		},
+		Order: OrderConfig{
+			PurchaseTimeout:         getDurationEnv("ORDER_PURCHASE_TIMEOUT", 30*time.Second),
+			SupplierBalanceBuffer:   getIntEnv("SUPPLIER_BALANCE_BUFFER", 50000),
+			SupplierBalanceMaxAge:   getDurationEnv("SUPPLIER_BALANCE_MAX_AGE", 10*time.Minute),
+			SupplierBalanceInterval: getDurationEnv("SUPPLIER_BALANCE_INTERVAL", 10*time.Minute),
+			StatusPollInterval:      getDurationEnv("ORDER_STATUS_POLL_INTERVAL", time.Minute),
+			StatusCheckAfter:        getDurationEnv("ORDER_STATUS_CHECK_AFTER", 5*time.Minute),
+			StatusCheckMaxBackoff:   getDurationEnv("ORDER_STATUS_CHECK_MAX_BACKOFF", time.Hour),
+			StatusEscalateAfter:     getDurationEnv("ORDER_STATUS_ESCALATE_AFTER", 2*time.Hour),
+			BillInquiryTTL:          getDurationEnv("POSTPAID_INQUIRY_TTL", 15*time.Minute),
+			DuplicateWindow:         getDurationEnv("ORDER_DUPLICATE_WINDOW", 10*time.Minute),
+		},
+		Sync: SyncConfig{
+			Interval:          getDurationEnv("PRODUCT_SYNC_INTERVAL", time.Hour),
+			PriceAlertPercent: float64(getIntEnv("PRICE_ALERT_PERCENT", 10)),
+		},
+		Nickname: NicknameConfig{
+			CheckerURL: getEnv("NICKNAME_CHECKER_URL", ""),
+			CheckerKey: getEnv("NICKNAME_CHECKER_KEY", ""),
+			Timeout:    getDurationEnv("NICKNAME_CHECKER_TIMEOUT", 10*time.Second),
+			CacheTTL:   getDurationEnv("NICKNAME_CACHE_TTL", 24*time.Hour),
+			MissTTL:    getDurationEnv("NICKNAME_MISS_TTL", 5*time.Minute),
+			RateLimit:  getIntEnv("NICKNAME_RATE_LIMIT", 30),
+			RateWindow: getDurationEnv("NICKNAME_RATE_WINDOW", time.Minute),
+		},
+		Reseller: ResellerConfig{
+			CallbackTimeout:      getDurationEnv("RESELLER_CALLBACK_TIMEOUT", 10*time.Second),
+			WebhookRetryInterval: getDurationEnv("RESELLER_WEBHOOK_RETRY_INTERVAL", 30*time.Second),
+			WebhookBackoff:       getDurationEnv("RESELLER_WEBHOOK_BACKOFF", time.Minute),
+			WebhookMaxBackoff:    getDurationEnv("RESELLER_WEBHOOK_MAX_BACKOFF", time.Hour),
+			WebhookMaxAttempts:   getIntEnv("RESELLER_WEBHOOK_MAX_ATTEMPTS", 8),
+		},
+		Gateway: GatewayConfig{
+			RelaySecret:    getEnv("GATEWAY_RELAY_SECRET", ""),
+			PINMaxAttempts: getIntEnv("GATEWAY_PIN_MAX_ATTEMPTS", 5),
+			PINLockout:     getDurationEnv("GATEWAY_PIN_LOCKOUT", 30*time.Minute),
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL: getDurationEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		},
 		ExternalAPI: ExternalAPIConfig{
 			Telkomsel: TelkomselConfig{
 				BaseURL:  getEnv("TELKOMSEL_BASE_URL", ""),
@@ -346,6 +538,17 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getListEnv splits a comma-separated variable, dropping empty items.
+func getListEnv(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -26,8 +26,8 @@ func NewRedisConnection(cfg *RedisConfig) *RedisConnection{
 func (c *RedisConnection) Connect()*redis.Client{
 	client := redis.NewClient(&redis.Options{
 		Addr:	  fmt.Sprintf("%s:%s",c.Config.Host,c.Config.Port),
-        Password: "", 
-        DB:		  0,  
+        Password: c.Config.Password,
+        DB:		  c.Config.DB,
         Protocol: 2,
 		MaxRetries: c.Config.MaxRetries,
 		PoolTimeout: c.Config.PoolTimeout,
@@ -1,46 +1,122 @@
 package handler
 
 import (
-	"context"
+	"crypto/hmac"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/config"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/crypto"
+	"github.com/wafi04/otomaxv2/pkg/response"
+	"github.com/wafi04/otomaxv2/pkg/token"
+	"github.com/wafi04/otomaxv2/pkg/validator"
 )
-var oauthState = "apasih1788wwWW"
-func GoogleLoginHandler(c *gin.Context) {
-	url := config.GoogleOauthConfig.AuthCodeURL(oauthState)
+
+// oauthStateCookie carries the OAuth state between the login redirect and the
+// callback as "<state>.<hmac>", so the callback can check both without any
+// server-side storage. A state started from GoogleLink ends in "~<userID>",
+// covered by the same signature, and links the Google account to that user.
+const (
+	oauthStateCookie = "oauth_state"
+	oauthStateTTL    = 10 * 60
+	oauthLinkMarker  = "~"
+)
+
+type AuthHandler struct {
+	authService  *services.AuthService
+	validator    *validator.Validator
+	stateSigner  *crypto.Crypto
+	secureCookie bool
+}
+
+func NewAuthHandler(authService *services.AuthService, stateSecret string, secureCookie bool) *AuthHandler {
+	return &AuthHandler{
+		authService:  authService,
+		validator:    validator.NewValidator(),
+		stateSigner:  crypto.NewCrypto(stateSecret),
+		secureCookie: secureCookie,
+	}
+}
+
+func (h *AuthHandler) GoogleLogin(c *gin.Context) {
+	h.startGoogleAuth(c, crypto.GenerateRandomString(32))
+}
+
+// GoogleLink starts the Google flow for the signed-in user; the callback links
+// the Google account to them instead of logging in.
+func (h *AuthHandler) GoogleLink(c *gin.Context) {
+	h.startGoogleAuth(c, crypto.GenerateRandomString(32)+oauthLinkMarker+strconv.Itoa(callerUser(c).ID))
+}
+
+func (h *AuthHandler) startGoogleAuth(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state+"."+h.stateSigner.GenerateHMAC(state, crypto.SHA256),
+		oauthStateTTL, "/", "", h.secureCookie, true)
+
+	url := config.GoogleOauthConfig.AuthCodeURL(state)
 	c.JSON(http.StatusOK, gin.H{
 		"login_url": url,
 	})
 }
 
-func GoogleCallbackHandler(c *gin.Context) {
-	ctx := context.Background()
-fmt.Println("CLIENT_ID:", config.GoogleOauthConfig.ClientID)
-fmt.Println("CLIENT_SECRET:", config.GoogleOauthConfig.ClientSecret)
-fmt.Println("REDIRECT_URL:", config.GoogleOauthConfig.RedirectURL)
-	// validasi state (disarankan simpan di session)
-	state := c.Query("state")
-	if state != oauthState {
+// validState checks the callback state against the signed cookie set at login
+// and returns the user to link, or 0 for a plain login. The cookie is cleared
+// either way so a state is only ever used once.
+func (h *AuthHandler) validState(c *gin.Context) (int, bool) {
+	cookie, err := c.Cookie(oauthStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, "/", "", h.secureCookie, true)
+	if err != nil {
+		return 0, false
+	}
+
+	state, signature, ok := strings.Cut(cookie, ".")
+	if !ok || state == "" || !h.stateSigner.VerifyHMAC(state, signature, crypto.SHA256) {
+		return 0, false
+	}
+	if !hmac.Equal([]byte(state), []byte(c.Query("state"))) {
+		return 0, false
+	}
+
+	_, linkUser, linking := strings.Cut(state, oauthLinkMarker)
+	if !linking {
+		return 0, true
+	}
+	userID, err := strconv.Atoi(linkUser)
+	if err != nil || userID <= 0 {
+		return 0, false
+	}
+	return userID, true
+}
+
+// GoogleCallback exchanges the OAuth code and either links the Google account
+// to the user who started GoogleLink, or logs the Google user in and returns a
+// fresh access/refresh token pair.
+func (h *AuthHandler) GoogleCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	linkUserID, ok := h.validState(c)
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid oauth state"})
 		return
 	}
 
 	// ambil code
 	code := c.Query("code")
-	token, err := config.GoogleOauthConfig.Exchange(ctx, code)
+	oauthToken, err := config.GoogleOauthConfig.Exchange(ctx, code)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "token exchange failed", "details": err.Error()})
 		return
 	}
-	fmt.Println("STATE:", c.Query("state"))
-fmt.Println("CODE:", c.Query("code"))
 
 	// pakai token untuk akses API userinfo
-	client := config.GoogleOauthConfig.Client(ctx, token)
+	client := config.GoogleOauthConfig.Client(ctx, oauthToken)
 	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user info", "details": err.Error()})
@@ -48,15 +124,196 @@ fmt.Println("CODE:", c.Query("code"))
 	}
 	defer resp.Body.Close()
 
-	var userInfo map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
+	var profile model.GoogleCallback
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decode user info", "details": err.Error()})
 		return
 	}
 
-	// ✅ berhasil login
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Google login success",
-		"user":    userInfo,
-	})
-}
\ No newline at end of file
+	if linkUserID != 0 {
+		user, err := h.authService.LinkGoogle(ctx, linkUserID, profile)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrEmailNotVerified):
+				response.ErrorResponse(c, http.StatusForbidden, "Link rejected", err.Error())
+			case errors.Is(err, services.ErrGoogleLinkConflict):
+				response.ErrorResponse(c, http.StatusConflict, "Link rejected", err.Error())
+			default:
+				response.ErrorResponse(c, http.StatusInternalServerError, "Failed to link Google account", err.Error())
+			}
+			return
+		}
+		response.SuccessResponse(c, http.StatusOK, "Google account linked", user)
+		return
+	}
+
+	login, err := h.authService.LoginWithGoogle(ctx, profile)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailNotVerified), errors.Is(err, services.ErrUserInactive):
+			response.ErrorResponse(c, http.StatusForbidden, "Login rejected", err.Error())
+		case errors.Is(err, services.ErrGoogleLinkRequired):
+			response.ErrorResponse(c, http.StatusConflict, "Login rejected", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to login", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Google login success", login)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var input model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, token.ErrInvalidToken), errors.Is(err, services.ErrUserInactive):
+			response.ErrorResponse(c, http.StatusUnauthorized, "Invalid refresh token", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to refresh token", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", tokens)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var input model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), input.RefreshToken); err != nil {
+		if errors.Is(err, token.ErrInvalidToken) {
+			response.ErrorResponse(c, http.StatusUnauthorized, "Invalid refresh token", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to logout", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Logout successful", nil)
+}
+
+// bindAndValidate binds the JSON body and runs the pkg/validator rules
+// (password strength, Indonesian phone numbers) declared in validate tags.
+func (h *AuthHandler) bindAndValidate(c *gin.Context, input interface{}) bool {
+	if err := c.ShouldBindJSON(input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return false
+	}
+	if errs := h.validator.ValidateStruct(input); len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, e := range errs {
+			messages = append(messages, e.Message)
+		}
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", strings.Join(messages, "; "))
+		return false
+	}
+	return true
+}
+
+func (h *AuthHandler) Register(c *gin.Context) {
+	var input model.RegisterRequest
+	if !h.bindAndValidate(c, &input) {
+		return
+	}
+
+	login, err := h.authService.Register(c.Request.Context(), input)
+	if err != nil {
+		if errors.Is(err, services.ErrUserExists) {
+			response.ErrorResponse(c, http.StatusConflict, "Registration failed", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to register", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "Registration successful", login)
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var input model.LoginRequest
+	if !h.bindAndValidate(c, &input) {
+		return
+	}
+
+	login, err := h.authService.Login(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			response.ErrorResponse(c, http.StatusUnauthorized, "Login failed", err.Error())
+		case errors.Is(err, services.ErrUserInactive):
+			response.ErrorResponse(c, http.StatusForbidden, "Login rejected", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to login", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Login successful", login)
+}
+
+func (h *AuthHandler) SendPhoneOTP(c *gin.Context) {
+	if err := h.authService.SendPhoneOTP(c.Request.Context(), callerUser(c)); err != nil {
+		otpError(c, err, "Failed to send verification code")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Verification code sent", nil)
+}
+
+func (h *AuthHandler) VerifyPhone(c *gin.Context) {
+	var input model.VerifyPhoneRequest
+	if !h.bindAndValidate(c, &input) {
+		return
+	}
+
+	if err := h.authService.VerifyPhone(c.Request.Context(), callerUser(c), input.Code); err != nil {
+		otpError(c, err, "Failed to verify phone")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Phone verified successfully", nil)
+}
+
+// SetPIN sets or changes the caller's text gateway PIN.
+func (h *AuthHandler) SetPIN(c *gin.Context) {
+	var input model.SetPINRequest
+	if !h.bindAndValidate(c, &input) {
+		return
+	}
+
+	if err := h.authService.SetPIN(c.Request.Context(), callerUser(c), input); err != nil {
+		if errors.Is(err, services.ErrPINIncorrect) {
+			response.ErrorResponse(c, http.StatusBadRequest, "Failed to set PIN", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to set PIN", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "PIN updated successfully", nil)
+}
+
+func otpError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrOTPInvalid), errors.Is(err, services.ErrOTPExpired),
+		errors.Is(err, services.ErrPhoneMissing), errors.Is(err, services.ErrPhoneVerified):
+		response.ErrorResponse(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, services.ErrOTPCooldown), errors.Is(err, services.ErrOTPAttempts):
+		response.ErrorResponse(c, http.StatusTooManyRequests, message, err.Error())
+	case errors.Is(err, services.ErrOTPUnavailable):
+		response.ErrorResponse(c, http.StatusServiceUnavailable, message, err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
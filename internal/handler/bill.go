@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type BillHandler struct {
+	billService *services.BillService
+}
+
+func NewBillHandler(billService *services.BillService) *BillHandler {
+	return &BillHandler{
+		billService: billService,
+	}
+}
+
+// Inquire looks up the customer's open postpaid bill and locks its total.
+func (h *BillHandler) Inquire(c *gin.Context) {
+	var input model.CreateBillInquiry
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+	input.Username = callerUsername(c)
+	input.Role = callerRole(c)
+
+	bill, err := h.billService.Inquire(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProductUnavailable), errors.Is(err, services.ErrProductCutOff),
+			errors.Is(err, services.ErrNotPostpaid):
+			response.ErrorResponse(c, http.StatusBadRequest, "Product unavailable", err.Error())
+		case errors.Is(err, services.ErrBillInquiryFailed):
+			response.ErrorResponse(c, http.StatusBadRequest, "Bill inquiry failed", err.Error())
+		case errors.Is(err, services.ErrBillBelowCost):
+			response.ErrorResponse(c, http.StatusUnprocessableEntity, "Bill cannot be paid with this product", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusBadGateway, "Bill inquiry failed", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "Bill retrieved successfully", bill)
+}
+
+func (h *BillHandler) GetByRefID(c *gin.Context) {
+	bill, err := h.billService.GetForUser(c.Request.Context(), c.Param("refId"), callerUsername(c), callerRole(c) == model.RoleAdmin)
+	if err != nil {
+		if errors.Is(err, services.ErrBillNotFound) {
+			response.ErrorResponse(c, http.StatusNotFound, "Bill not found", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get bill", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Bill retrieved successfully", bill)
+}
+
+// Pay creates the transaction that pays an inquired bill.
+func (h *BillHandler) Pay(c *gin.Context) {
+	var input model.PayBill
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+	input.Username = callerUsername(c)
+	input.Role = callerRole(c)
+
+	trx, err := h.billService.Pay(c.Request.Context(), c.Param("refId"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBillNotFound):
+			response.ErrorResponse(c, http.StatusNotFound, "Bill not found", err.Error())
+		case errors.Is(err, services.ErrBillExpired), errors.Is(err, services.ErrBillPaid):
+			response.ErrorResponse(c, http.StatusConflict, "Bill cannot be paid", err.Error())
+		case errors.Is(err, services.ErrPaymentMethod), errors.Is(err, services.ErrUsernameRequired):
+			response.ErrorResponse(c, http.StatusBadRequest, "Invalid payment method", err.Error())
+		case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrWalletNotFound):
+			response.ErrorResponse(c, http.StatusBadRequest, "Payment failed", err.Error())
+		case errors.Is(err, services.ErrSupplierBalanceLow):
+			response.ErrorResponse(c, http.StatusServiceUnavailable, "Product unavailable", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to pay bill", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "Transaction created successfully", trx)
+}
@@ -58,7 +58,7 @@ func (h *CategoryHandler) GetCategoryByCode(c *gin.Context) {
 	}
 
 	// Call service dengan parameter optional
-	cat, err := h.categoryService.GetCategoryByCode(c.Request.Context(), codeParam, subCategoryId)
+	cat, err := h.categoryService.GetCategoryByCode(c.Request.Context(), codeParam, subCategoryId, callerRole(c))
 	if err != nil {
 		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get category", err.Error())
 		return
@@ -1,19 +1,18 @@
 package handler
 
 import (
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/wafi04/otomaxv2/internal/config"
 	"github.com/wafi04/otomaxv2/internal/integrations/duitku"
 	"github.com/wafi04/otomaxv2/internal/model"
 	"github.com/wafi04/otomaxv2/internal/services"
 	"github.com/wafi04/otomaxv2/pkg/response"
-	"github.com/wafi04/otomaxv2/pkg/utils"
 )
 
 type DepositHandler struct {
-	cfg         config.Config
 	depoService *services.DepositService
 }
 
@@ -25,48 +24,25 @@ func NewDepositHandler(depoService *services.DepositService) *DepositHandler {
 
 func (h *DepositHandler) Create(c *gin.Context) {
 	var input model.RequestFormClient
-	duitkuMethod := duitku.NewDuitkuService(&h.cfg)
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
 		return
 	}
-	depStr := "DEP"
-	callbackUrl := "http://localhost:8080/api/callback/duitku"
-
-	duitkuCall, err := duitkuMethod.CreateTransaction(c, &duitku.DuitkuCreateTransactionParams{
-		PaymentAmount:   input.Amount,
-		MerchantOrderId: utils.GenerateUniqeID(&depStr),
-		ProductDetails:  "Deposit",
-		PaymentCode:     input.Method,
-		CallbackUrl:     &callbackUrl,
-		ReturnUrl:       &callbackUrl,
-	})
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create category", err.Error())
-		return
-	}
-
-	_, err = h.depoService.Create(c.Request.Context(), model.CreateDeposit{
-		Amount:            input.Amount,
-		Method:            input.Method,
-		Username:          "",
-		DestinationNumber: "",
-		PaymentReferee:    &duitkuCall.Reference,
-	})
 
+	duitkuCall, err := h.depoService.CreatePayment(c.Request.Context(), input, callerUsername(c))
 	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create category", err.Error())
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create deposit", err.Error())
 		return
 	}
 
-	response.SuccessResponse(c, http.StatusCreated, "Category created successfully", nil)
+	response.SuccessResponse(c, http.StatusCreated, "Deposit created successfully", duitkuCall)
 
 }
 
 func (h *DepositHandler) GetAll(c *gin.Context) {
 	search := c.Query("search")
-	status := c.Query("search")
+	status := c.Query("status")
 	page := c.DefaultQuery("page", "1")
 	limit := c.DefaultQuery("limit", "10")
 
@@ -95,3 +71,30 @@ func (h *DepositHandler) GetAll(c *gin.Context) {
 
 	response.SuccessResponse(c, http.StatusOK, "Deposits retrieved successfully", responses)
 }
+
+// DuitkuCallback receives the form-encoded payment notification from Duitku.
+func (h *DepositHandler) DuitkuCallback(c *gin.Context) {
+	var params duitku.DuitkuCallbackParams
+	if err := c.ShouldBind(&params); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid callback body", err.Error())
+		return
+	}
+
+	err := h.depoService.HandleDuitkuCallback(c.Request.Context(), &params)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSignature):
+			log.Printf("Duitku callback rejected: invalid signature for %s from %s", params.MerchantOrderId, c.ClientIP())
+			response.ErrorResponse(c, http.StatusUnauthorized, "Invalid signature", err.Error())
+		case errors.Is(err, services.ErrDepositNotFound):
+			response.ErrorResponse(c, http.StatusNotFound, "Deposit not found", err.Error())
+		case errors.Is(err, services.ErrAmountMismatch):
+			response.ErrorResponse(c, http.StatusBadRequest, "Invalid amount", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to process callback", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Callback processed successfully", nil)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type GatewayHandler struct {
+	gatewayService *services.GatewayService
+	relaySecret    string
+}
+
+func NewGatewayHandler(gatewayService *services.GatewayService, relaySecret string) *GatewayHandler {
+	return &GatewayHandler{
+		gatewayService: gatewayService,
+		relaySecret:    relaySecret,
+	}
+}
+
+// Message runs a text command forwarded by a chat or SMS relay. The relay
+// authenticates with the shared secret in X-Gateway-Key; the sender is
+// authenticated by the service.
+func (h *GatewayHandler) Message(c *gin.Context) {
+	key := c.GetHeader("X-Gateway-Key")
+	if h.relaySecret == "" || subtle.ConstantTimeCompare([]byte(key), []byte(h.relaySecret)) != 1 {
+		log.Printf("Gateway message rejected: invalid relay key from %s", c.ClientIP())
+		response.ErrorResponse(c, http.StatusUnauthorized, "Invalid gateway key", "gateway key verification failed")
+		return
+	}
+
+	var input model.GatewayMessage
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	reply := h.gatewayService.Handle(c.Request.Context(), input)
+	response.SuccessResponse(c, http.StatusOK, "Message processed", model.GatewayReply{Reply: reply})
+}
+
+// SetKeyword assigns the keyword a product is ordered by over the gateway.
+func (h *GatewayHandler) SetKeyword(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid product id", err.Error())
+		return
+	}
+	var input model.SetProductKeyword
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	if err := h.gatewayService.SetKeyword(c.Request.Context(), productID, input.Keyword); err != nil {
+		switch {
+		case errors.Is(err, services.ErrKeywordInvalid), errors.Is(err, services.ErrKeywordReserved):
+			response.ErrorResponse(c, http.StatusBadRequest, "Failed to set keyword", err.Error())
+		case errors.Is(err, services.ErrKeywordTaken):
+			response.ErrorResponse(c, http.StatusConflict, "Failed to set keyword", err.Error())
+		case errors.Is(err, services.ErrProductNotFound):
+			response.ErrorResponse(c, http.StatusNotFound, "Failed to set keyword", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to set keyword", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Keyword updated successfully", nil)
+}
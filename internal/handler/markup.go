@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type MarkupRuleHandler struct {
+	pricingService *services.PricingService
+}
+
+func NewMarkupRuleHandler(pricingService *services.PricingService) *MarkupRuleHandler {
+	return &MarkupRuleHandler{
+		pricingService: pricingService,
+	}
+}
+
+func (h *MarkupRuleHandler) Create(c *gin.Context) {
+	var input model.CreateMarkupRule
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	rule, err := h.pricingService.Create(c.Request.Context(), input)
+	if err != nil {
+		markupRuleError(c, err, "Failed to create markup rule")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "Markup rule created successfully", rule)
+}
+
+func (h *MarkupRuleHandler) GetAll(c *gin.Context) {
+	rules, err := h.pricingService.GetAll(c.Request.Context())
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch markup rules", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Markup rules retrieved successfully", rules)
+}
+
+func (h *MarkupRuleHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID parameter", err.Error())
+		return
+	}
+
+	var input model.CreateMarkupRule
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	rule, err := h.pricingService.Update(c.Request.Context(), id, input)
+	if err != nil {
+		markupRuleError(c, err, "Failed to update markup rule")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Markup rule updated successfully", rule)
+}
+
+func (h *MarkupRuleHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID parameter", err.Error())
+		return
+	}
+
+	if err := h.pricingService.Delete(c.Request.Context(), id); err != nil {
+		markupRuleError(c, err, "Failed to delete markup rule")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Markup rule deleted successfully", nil)
+}
+
+// Preview shows the per-role selling price a SKU would get from the current
+// rules, optionally including an unsaved draft rule.
+func (h *MarkupRuleHandler) Preview(c *gin.Context) {
+	var input model.PricePreviewRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	preview, err := h.pricingService.Preview(c.Request.Context(), input)
+	if err != nil {
+		markupRuleError(c, err, "Failed to preview price")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Price preview generated successfully", preview)
+}
+
+func markupRuleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMarkupRule):
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid markup rule", err.Error())
+	case errors.Is(err, services.ErrMarkupRuleNotFound):
+		response.ErrorResponse(c, http.StatusNotFound, "Markup rule not found", err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type NicknameHandler struct {
+	nicknameService *services.NicknameService
+}
+
+func NewNicknameHandler(nicknameService *services.NicknameService) *NicknameHandler {
+	return &NicknameHandler{
+		nicknameService: nicknameService,
+	}
+}
+
+// Check returns the in-game nickname for the account in the body, so the
+// customer can confirm it before paying.
+func (h *NicknameHandler) Check(c *gin.Context) {
+	var input model.CheckNicknameRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	result, err := h.nicknameService.Check(c.Request.Context(), c.Param("code"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCategoryNotFound), errors.Is(err, services.ErrNicknameNotFound):
+			response.ErrorResponse(c, http.StatusNotFound, "Failed to check nickname", err.Error())
+		case errors.Is(err, services.ErrNicknameNotSupported), errors.Is(err, services.ErrZoneIDRequired):
+			response.ErrorResponse(c, http.StatusBadRequest, "Failed to check nickname", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusBadGateway, "Failed to check nickname", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Nickname retrieved successfully", result)
+}
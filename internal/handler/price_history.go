@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+// defaultHistoryDays is the chart window when the request does not set days.
+const defaultHistoryDays = 30
+
+type PriceHistoryHandler struct {
+	priceHistoryService *services.PriceHistoryService
+}
+
+func NewPriceHistoryHandler(priceHistoryService *services.PriceHistoryService) *PriceHistoryHandler {
+	return &PriceHistoryHandler{
+		priceHistoryService: priceHistoryService,
+	}
+}
+
+func (h *PriceHistoryHandler) GetByProduct(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultHistoryDays)))
+	if err != nil || days <= 0 {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid days", "days must be a positive number")
+		return
+	}
+
+	history, err := h.priceHistoryService.GetProductHistory(c.Request.Context(), productID, days)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch price history", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Price history retrieved successfully", history)
+}
+
+func (h *PriceHistoryHandler) GetAlerts(c *gin.Context) {
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "10")
+
+	paginationResult := response.CalculatePagination(&page, &limit)
+
+	filter := model.FilterPriceAlert{
+		Type:   c.Query("type"),
+		Limit:  paginationResult.Take,
+		Offset: paginationResult.Skip,
+	}
+	if runID := c.Query("syncRunId"); runID != "" {
+		id, err := strconv.Atoi(runID)
+		if err != nil {
+			response.ErrorResponse(c, http.StatusBadRequest, "Invalid sync run ID", err.Error())
+			return
+		}
+		filter.SyncRunID = id
+	}
+
+	data, totalCount, err := h.priceHistoryService.GetAlerts(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch price alerts", err.Error())
+		return
+	}
+
+	responses := response.CreatePaginatedResponse(
+		data,
+		paginationResult.CurrentPage,
+		paginationResult.ItemsPerPage,
+		totalCount,
+	)
+
+	response.SuccessResponse(c, http.StatusOK, "Price alerts retrieved successfully", responses)
+}
@@ -21,7 +21,7 @@ func NewProductHandler(service *services.ProductService) *ProductHandler {
 
 
 func (h *ProductHandler) GetAll(c *gin.Context) {
-	productList, err := h.productHandler.GetAll(c)
+	productList, err := h.productHandler.GetAll(c, callerRole(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get news"})
 		return
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type ResellerHandler struct {
+	resellerService *services.ResellerService
+}
+
+func NewResellerHandler(resellerService *services.ResellerService) *ResellerHandler {
+	return &ResellerHandler{
+		resellerService: resellerService,
+	}
+}
+
+// IssueKey creates or rotates the caller's API key and returns it once.
+func (h *ResellerHandler) IssueKey(c *gin.Context) {
+	issued, err := h.resellerService.IssueKey(c.Request.Context(), callerUser(c))
+	if err != nil {
+		if errors.Is(err, services.ErrResellerRole) {
+			response.ErrorResponse(c, http.StatusForbidden, "Failed to issue API key", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to issue API key", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "API key issued successfully", issued)
+}
+
+func (h *ResellerHandler) GetSettings(c *gin.Context) {
+	settings, err := h.resellerService.GetSettings(c.Request.Context(), callerUser(c).ID)
+	if err != nil {
+		resellerSettingsError(c, err, "Failed to get reseller settings")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Reseller settings retrieved successfully", settings)
+}
+
+func (h *ResellerHandler) UpdateSettings(c *gin.Context) {
+	var input model.UpdateResellerSettings
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	settings, err := h.resellerService.UpdateSettings(c.Request.Context(), callerUser(c).ID, input)
+	if err != nil {
+		resellerSettingsError(c, err, "Failed to update reseller settings")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Reseller settings updated successfully", settings)
+}
+
+// SetActive lets admins suspend or restore a reseller's API access.
+func (h *ResellerHandler) SetActive(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid user id", err.Error())
+		return
+	}
+	var input struct {
+		IsActive *bool `json:"isActive" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+
+	if err := h.resellerService.SetActive(c.Request.Context(), userID, *input.IsActive); err != nil {
+		resellerSettingsError(c, err, "Failed to update reseller")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Reseller updated successfully", nil)
+}
+
+func resellerSettingsError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrResellerNotFound):
+		response.ErrorResponse(c, http.StatusNotFound, message, err.Error())
+	case errors.Is(err, services.ErrResellerSettings):
+		response.ErrorResponse(c, http.StatusBadRequest, message, err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
+
+// PriceList returns the reseller's prices. Signed with subject "pricelist".
+func (h *ResellerHandler) PriceList(c *gin.Context) {
+	key, _, ok := h.authenticate(c, func(model.H2HRequest) string { return services.ResellerSignPriceList })
+	if !ok {
+		return
+	}
+
+	products, err := h.resellerService.PriceList(c.Request.Context(), key)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch price list", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Price list retrieved successfully", products)
+}
+
+// Balance returns the reseller's wallet balance. Signed with subject "depo".
+func (h *ResellerHandler) Balance(c *gin.Context) {
+	key, _, ok := h.authenticate(c, func(model.H2HRequest) string { return services.ResellerSignBalance })
+	if !ok {
+		return
+	}
+
+	balance, err := h.resellerService.Balance(c.Request.Context(), key)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch balance", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Balance retrieved successfully", balance)
+}
+
+// Purchase places an order paid from the reseller's balance. Signed with the
+// ref_id; repeating a ref_id returns the existing order.
+func (h *ResellerHandler) Purchase(c *gin.Context) {
+	key, req, ok := h.authenticate(c, func(req model.H2HRequest) string { return req.RefID })
+	if !ok {
+		return
+	}
+
+	trx, err := h.resellerService.Purchase(c.Request.Context(), key, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRefIDRequired), errors.Is(err, services.ErrProductUnavailable),
+			errors.Is(err, services.ErrProductCutOff), errors.Is(err, services.ErrPostpaidProduct):
+			response.ErrorResponse(c, http.StatusBadRequest, "Purchase failed", err.Error())
+		case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrWalletNotFound):
+			response.ErrorResponse(c, http.StatusBadRequest, "Purchase failed", err.Error())
+		case errors.Is(err, services.ErrSupplierBalanceLow):
+			response.ErrorResponse(c, http.StatusServiceUnavailable, "Purchase failed", err.Error())
+		case errors.Is(err, services.ErrRefIDConflict), errors.Is(err, services.ErrDuplicateOrder):
+			response.ErrorResponse(c, http.StatusConflict, "Purchase failed", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Purchase failed", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Transaction processed", trx)
+}
+
+// Status reports an order by the reseller's ref_id. Signed with the ref_id.
+func (h *ResellerHandler) Status(c *gin.Context) {
+	key, req, ok := h.authenticate(c, func(req model.H2HRequest) string { return req.RefID })
+	if !ok {
+		return
+	}
+
+	trx, err := h.resellerService.Status(c.Request.Context(), key, req.RefID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRefIDRequired):
+			response.ErrorResponse(c, http.StatusBadRequest, "Status check failed", err.Error())
+		case errors.Is(err, services.ErrTransactionNotFound):
+			response.ErrorResponse(c, http.StatusNotFound, "Status check failed", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Status check failed", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Transaction retrieved successfully", trx)
+}
+
+// authenticate binds the H2H body and checks its signature over the subject
+// picked from it.
+func (h *ResellerHandler) authenticate(c *gin.Context, subject func(model.H2HRequest) string) (*model.ResellerKey, model.H2HRequest, bool) {
+	var req model.H2HRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return nil, req, false
+	}
+
+	key, err := h.resellerService.Authenticate(c.Request.Context(), req, subject(req), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrResellerAuth):
+			response.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		case errors.Is(err, services.ErrResellerIP):
+			response.ErrorResponse(c, http.StatusForbidden, "Forbidden", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to authenticate", err.Error())
+		}
+		return nil, req, false
+	}
+	return key, req, true
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+// callerRole returns the role stored on the request context, defaulting to
+// RoleMember for anonymous callers.
+func callerRole(c *gin.Context) model.UserRole {
+	if v, ok := c.Get(model.RoleContextKey); ok {
+		if role, ok := v.(model.UserRole); ok {
+			return role
+		}
+	}
+	return model.RoleMember
+}
+
+// callerUser returns the authenticated user, or nil for anonymous callers.
+func callerUser(c *gin.Context) *model.UserData {
+	if v, ok := c.Get(model.UserContextKey); ok {
+		if user, ok := v.(*model.UserData); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// callerUsername returns the authenticated user's username, or "" when anonymous.
+func callerUsername(c *gin.Context) string {
+	if user := callerUser(c); user != nil {
+		return user.Username
+	}
+	return ""
+}
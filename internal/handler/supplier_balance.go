@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type SupplierBalanceHandler struct {
+	balanceService *services.SupplierBalanceService
+}
+
+func NewSupplierBalanceHandler(balanceService *services.SupplierBalanceService) *SupplierBalanceHandler {
+	return &SupplierBalanceHandler{
+		balanceService: balanceService,
+	}
+}
+
+func (h *SupplierBalanceHandler) GetAll(c *gin.Context) {
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "10")
+
+	paginationResult := response.CalculatePagination(&page, &limit)
+
+	data, totalCount, err := h.balanceService.GetAll(c.Request.Context(), model.FilterSupplierBalance{
+		Provider: c.Query("provider"),
+		Limit:    paginationResult.Take,
+		Offset:   paginationResult.Skip,
+	})
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch supplier balances", err.Error())
+		return
+	}
+
+	responses := response.CreatePaginatedResponse(
+		data,
+		paginationResult.CurrentPage,
+		paginationResult.ItemsPerPage,
+		totalCount,
+	)
+
+	response.SuccessResponse(c, http.StatusOK, "Supplier balances retrieved successfully", responses)
+}
+
+// Refresh checks every provider's balance right now and returns the new snapshots.
+func (h *SupplierBalanceHandler) Refresh(c *gin.Context) {
+	snapshots := h.balanceService.RefreshAll(c.Request.Context())
+
+	response.SuccessResponse(c, http.StatusOK, "Supplier balances refreshed", snapshots)
+}
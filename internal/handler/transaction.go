@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/integrations/duitku"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/crypto"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type TransactionHandler struct {
+	transactionService     *services.TransactionService
+	digiflazzWebhookSecret string
+}
+
+func NewTransactionHandler(transactionService *services.TransactionService, digiflazzWebhookSecret string) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService:     transactionService,
+		digiflazzWebhookSecret: digiflazzWebhookSecret,
+	}
+}
+
+func (h *TransactionHandler) Create(c *gin.Context) {
+	var input model.CreateTransaction
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return
+	}
+	input.Username = callerUsername(c)
+	input.Role = callerRole(c)
+
+	trx, err := h.transactionService.Create(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProductUnavailable), errors.Is(err, services.ErrProductCutOff),
+			errors.Is(err, services.ErrPostpaidProduct):
+			response.ErrorResponse(c, http.StatusBadRequest, "Product unavailable", err.Error())
+		case errors.Is(err, services.ErrPaymentMethod), errors.Is(err, services.ErrUsernameRequired):
+			response.ErrorResponse(c, http.StatusBadRequest, "Invalid payment method", err.Error())
+		case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrWalletNotFound):
+			response.ErrorResponse(c, http.StatusBadRequest, "Payment failed", err.Error())
+		case errors.Is(err, services.ErrSupplierBalanceLow):
+			response.ErrorResponse(c, http.StatusServiceUnavailable, "Product unavailable", err.Error())
+		case errors.Is(err, services.ErrDuplicateOrder):
+			response.ErrorResponse(c, http.StatusConflict, "Duplicate order", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to create transaction", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "Transaction created successfully", trx)
+}
+
+func (h *TransactionHandler) GetByRefID(c *gin.Context) {
+	trx, err := h.transactionService.GetByRefID(c.Request.Context(), c.Param("refId"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get transaction", err.Error())
+		return
+	}
+	// members only ever see their own orders
+	if trx == nil || (callerRole(c) != model.RoleAdmin && trx.Username != callerUsername(c)) {
+		response.ErrorResponse(c, http.StatusNotFound, "Transaction not found", "No transaction found with the given ref id")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Transaction retrieved successfully", trx)
+}
+
+func (h *TransactionHandler) GetAll(c *gin.Context) {
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "10")
+
+	paginationResult := response.CalculatePagination(&page, &limit)
+
+	// members only ever see their own orders
+	username := c.Query("username")
+	if callerRole(c) != model.RoleAdmin {
+		username = callerUsername(c)
+	}
+
+	data, totalCount, err := h.transactionService.GetAll(c.Request.Context(), model.FilterTransaction{
+		Search:   c.Query("search"),
+		Status:   c.Query("status"),
+		Username: username,
+		Limit:    paginationResult.Take,
+		Offset:   paginationResult.Skip,
+	})
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch transactions", err.Error())
+		return
+	}
+
+	responses := response.CreatePaginatedResponse(
+		data,
+		paginationResult.CurrentPage,
+		paginationResult.ItemsPerPage,
+		totalCount,
+	)
+
+	response.SuccessResponse(c, http.StatusOK, "Transactions retrieved successfully", responses)
+}
+
+// GetEscalated lists orders still Pending past the escalation age, for admins
+// to follow up with the provider.
+func (h *TransactionHandler) GetEscalated(c *gin.Context) {
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "10")
+
+	paginationResult := response.CalculatePagination(&page, &limit)
+
+	data, totalCount, err := h.transactionService.GetEscalated(c.Request.Context(), paginationResult.Take, paginationResult.Skip)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch escalated transactions", err.Error())
+		return
+	}
+
+	responses := response.CreatePaginatedResponse(
+		data,
+		paginationResult.CurrentPage,
+		paginationResult.ItemsPerPage,
+		totalCount,
+	)
+
+	response.SuccessResponse(c, http.StatusOK, "Escalated transactions retrieved successfully", responses)
+}
+
+// DigiflazzCallback receives transaction status updates from Digiflazz. The body
+// is signed with HMAC-SHA1 using the webhook secret and sent in X-Hub-Signature.
+func (h *TransactionHandler) DigiflazzCallback(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid callback body", err.Error())
+		return
+	}
+
+	signature := strings.TrimPrefix(c.GetHeader("X-Hub-Signature"), "sha1=")
+	if h.digiflazzWebhookSecret == "" || signature == "" ||
+		!crypto.NewCrypto(h.digiflazzWebhookSecret).VerifyHMAC(string(body), signature, crypto.SHA1) {
+		log.Printf("Digiflazz callback rejected: invalid signature from %s", c.ClientIP())
+		response.ErrorResponse(c, http.StatusUnauthorized, "Invalid signature", "signature verification failed")
+		return
+	}
+
+	var payload digiflazz.CallbackPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid callback body", err.Error())
+		return
+	}
+
+	if err := h.transactionService.HandleDigiflazzCallback(c.Request.Context(), payload); err != nil {
+		if errors.Is(err, services.ErrTransactionNotFound) {
+			response.ErrorResponse(c, http.StatusNotFound, "Transaction not found", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to process callback", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Callback processed successfully", nil)
+}
+
+// DuitkuCallback receives the payment notification for gateway-paid orders.
+func (h *TransactionHandler) DuitkuCallback(c *gin.Context) {
+	var params duitku.DuitkuCallbackParams
+	if err := c.ShouldBind(&params); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid callback body", err.Error())
+		return
+	}
+
+	err := h.transactionService.HandleDuitkuCallback(c.Request.Context(), &params)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSignature):
+			log.Printf("Duitku callback rejected: invalid signature for %s from %s", params.MerchantOrderId, c.ClientIP())
+			response.ErrorResponse(c, http.StatusUnauthorized, "Invalid signature", err.Error())
+		case errors.Is(err, services.ErrTransactionNotFound):
+			response.ErrorResponse(c, http.StatusNotFound, "Transaction not found", err.Error())
+		case errors.Is(err, services.ErrAmountMismatch):
+			response.ErrorResponse(c, http.StatusBadRequest, "Invalid amount", err.Error())
+		default:
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to process callback", err.Error())
+		}
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Callback processed successfully", nil)
+}
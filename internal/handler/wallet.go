@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type WalletHandler struct {
+	walletService *services.WalletService
+}
+
+func NewWalletHandler(walletService *services.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// ownsWallet lets members read only their own wallet; admins can read any.
+func ownsWallet(c *gin.Context) bool {
+	if callerRole(c) == model.RoleAdmin || callerUsername(c) == c.Param("username") {
+		return true
+	}
+	response.ErrorResponse(c, http.StatusForbidden, "Forbidden", "wallet belongs to another user")
+	return false
+}
+
+func (h *WalletHandler) GetBalance(c *gin.Context) {
+	if !ownsWallet(c) {
+		return
+	}
+	balance, err := h.walletService.GetBalance(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrWalletNotFound) {
+			response.ErrorResponse(c, http.StatusNotFound, "Wallet not found", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to get balance", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Balance retrieved successfully", balance)
+}
+
+func (h *WalletHandler) GetLedger(c *gin.Context) {
+	if !ownsWallet(c) {
+		return
+	}
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "10")
+
+	paginationResult := response.CalculatePagination(&page, &limit)
+
+	data, totalCount, err := h.walletService.GetLedger(c.Request.Context(), c.Param("username"), paginationResult.Take, paginationResult.Skip)
+	if err != nil {
+		if errors.Is(err, services.ErrWalletNotFound) {
+			response.ErrorResponse(c, http.StatusNotFound, "Wallet not found", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch ledger", err.Error())
+		return
+	}
+
+	responses := response.CreatePaginatedResponse(
+		data,
+		paginationResult.CurrentPage,
+		paginationResult.ItemsPerPage,
+		totalCount,
+	)
+
+	response.SuccessResponse(c, http.StatusOK, "Ledger retrieved successfully", responses)
+}
+
+func (h *WalletHandler) Audit(c *gin.Context) {
+	audit, err := h.walletService.Audit(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrWalletNotFound) {
+			response.ErrorResponse(c, http.StatusNotFound, "Wallet not found", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to audit wallet", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Wallet audited successfully", audit)
+}
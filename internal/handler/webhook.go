@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// GetAll lists reseller webhook deliveries, newest first.
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	page := c.DefaultQuery("page", "1")
+	limit := c.DefaultQuery("limit", "10")
+
+	paginationResult := response.CalculatePagination(&page, &limit)
+
+	data, totalCount, err := h.webhookService.GetAll(c.Request.Context(), model.FilterWebhookDelivery{
+		Search:   c.Query("search"),
+		Status:   c.Query("status"),
+		Username: c.Query("username"),
+		Limit:    paginationResult.Take,
+		Offset:   paginationResult.Skip,
+	})
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch webhook deliveries", err.Error())
+		return
+	}
+
+	responses := response.CreatePaginatedResponse(
+		data,
+		paginationResult.CurrentPage,
+		paginationResult.ItemsPerPage,
+		totalCount,
+	)
+
+	response.SuccessResponse(c, http.StatusOK, "Webhook deliveries retrieved successfully", responses)
+}
+
+// GetByID returns a delivery with every attempt made for it.
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook delivery id", err.Error())
+		return
+	}
+
+	delivery, err := h.webhookService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			response.ErrorResponse(c, http.StatusNotFound, "Webhook delivery not found", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch webhook delivery", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Webhook delivery retrieved successfully", delivery)
+}
+
+// Resend posts the delivery again now and returns its updated history.
+func (h *WebhookHandler) Resend(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook delivery id", err.Error())
+		return
+	}
+
+	delivery, err := h.webhookService.Resend(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			response.ErrorResponse(c, http.StatusNotFound, "Webhook delivery not found", err.Error())
+			return
+		}
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to resend webhook", err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Webhook resent", delivery)
+}
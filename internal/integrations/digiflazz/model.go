@@ -1,5 +1,7 @@
 package digiflazz
 
+import "encoding/json"
+
 type ProductData struct {
 	BuyerProductStatus  bool   `json:"buyer_product_status"`
 	BuyerSkuCode        string `json:"buyer_sku_code"`
@@ -18,6 +20,21 @@ type ProductData struct {
 	Brand               string `json:"brand"`
 }
 
+// PostpaidProductData is one row of the postpaid (pasca) price list. Admin is
+// the fee Digiflazz adds to each bill and Commission what it pays back to us.
+type PostpaidProductData struct {
+	ProductName         string `json:"product_name"`
+	Category            string `json:"category"`
+	Brand               string `json:"brand"`
+	SellerName          string `json:"seller_name"`
+	Admin               int    `json:"admin"`
+	Commission          int    `json:"commission"`
+	BuyerSkuCode        string `json:"buyer_sku_code"`
+	BuyerProductStatus  bool   `json:"buyer_product_status"`
+	SellerProductStatus bool   `json:"seller_product_status"`
+	Desc                string `json:"desc"`
+}
+
 type CreateTransactionToDigiflazz struct {
 	BuyerSKUCode string `json:"buyer_sku_code"`
 	CustomerNo   string `json:"customer_no"`
@@ -33,13 +50,57 @@ type TransactionCreateDigiflazzResponse struct {
 		Status         string `json:"status"`
 		RC             string `json:"rc"`
 		SN             string `json:"sn"`
-		BuyerLastSaldo int    `json:"buyer_last_saldo"`
+		BuyerLastSaldo *int   `json:"buyer_last_saldo"`
 		Price          int    `json:"price"`
 		Tele           string `json:"tele"`
 		WA             string `json:"wa"`
 	} `json:"data"`
 }
 
+// PostpaidResponse answers the inq-pasca, pay-pasca and status-pasca commands.
+// Price is what Digiflazz charges us; SellingPrice is the bill plus admin fee.
+type PostpaidResponse struct {
+	Data struct {
+		RefID          string `json:"ref_id"`
+		CustomerNo     string `json:"customer_no"`
+		CustomerName   string `json:"customer_name"`
+		BuyerSKUCode   string `json:"buyer_sku_code"`
+		Admin          int    `json:"admin"`
+		Message        string `json:"message"`
+		Status         string `json:"status"`
+		RC             string `json:"rc"`
+		SN             string `json:"sn"`
+		BuyerLastSaldo *int   `json:"buyer_last_saldo"`
+		Price          int    `json:"price"`
+		SellingPrice   int    `json:"selling_price"`
+		// Desc varies per product type; see BillDetails.
+		Desc json.RawMessage `json:"desc"`
+	} `json:"data"`
+}
+
+// PostpaidBillDetail is one billing period of an inquired bill.
+type PostpaidBillDetail struct {
+	Periode      string `json:"periode"`
+	NilaiTagihan int    `json:"nilai_tagihan"`
+	Admin        int    `json:"admin"`
+	Denda        int    `json:"denda"`
+}
+
+// BillDetails returns the billing periods listed in desc.detail, or nil when
+// the product describes its bill differently.
+func (r *PostpaidResponse) BillDetails() []PostpaidBillDetail {
+	var desc struct {
+		Detail []PostpaidBillDetail `json:"detail"`
+	}
+	if err := json.Unmarshal(r.Data.Desc, &desc); err != nil {
+		return nil
+	}
+	return desc.Detail
+}
+
+// CallbackPayload is the body Digiflazz posts to cb_url when a transaction changes status.
+type CallbackPayload = TransactionCreateDigiflazzResponse
+
 type DigiflazzErrorResponse struct {
 	Data struct {
 		Message string `json:"message"`
@@ -54,6 +115,7 @@ type DigiflazzResponse struct {
 type DigiConfig struct {
 	DigiKey      string
 	DigiUsername string
+	CallbackURL  string
 }
 
 type DigiflazzService struct {
@@ -70,6 +132,9 @@ type InternalProduct struct {
 	CostPrice     int    `json:"cost_price"`
 	SellingPrice  int    `json:"selling_price"`
 	ProfitMargin  int    `json:"profit_margin"`
+	PriceMember   int    `json:"price_member"`
+	PricePlatinum int    `json:"price_platinum"`
+	PriceAdmin    int    `json:"price_admin"`
 	Stock         int    `json:"stock"`
 	IsUnlimited   bool   `json:"is_unlimited"`
 	IsActive      bool   `json:"is_active"`
@@ -78,6 +143,7 @@ type InternalProduct struct {
 	StartCutOff   string `json:"start_cut_off"`
 	EndCutOff     string `json:"end_cut_off"`
 	SupportMulti  bool   `json:"support_multi"`
+	IsPostpaid    bool   `json:"is_postpaid"`
 	Provider      string `json:"provider"`
 	CategoryID    *int   `json:"categoryId"`
 	SubCategoryID *int   `json:"subCategoryId"`
@@ -0,0 +1,83 @@
+package digiflazz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/integrations/nickname"
+)
+
+// plnBrand is the brand Digiflazz uses for PLN tokens, the only product its
+// inquiry endpoint can resolve a customer name for.
+const plnBrand = "PLN"
+
+var _ nickname.Checker = (*NicknameChecker)(nil)
+
+// NicknameChecker resolves PLN customer names through Digiflazz inquiry-pln.
+type NicknameChecker struct {
+	service *DigiflazzService
+}
+
+func NewNicknameChecker(service *DigiflazzService) *NicknameChecker {
+	return &NicknameChecker{service: service}
+}
+
+func (n *NicknameChecker) Name() string {
+	return ProviderSlug
+}
+
+func (n *NicknameChecker) Check(ctx context.Context, req nickname.Request) (string, error) {
+	if !strings.EqualFold(req.Brand, plnBrand) {
+		return "", nickname.ErrNotSupported
+	}
+
+	cfg := n.service.config
+	payload, err := json.Marshal(map[string]string{
+		"username":    cfg.DigiUsername,
+		"customer_no": req.UserID,
+		"sign":        n.service.generateSign(cfg.DigiUsername, cfg.DigiKey, req.UserID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.digiflazz.com/v1/inquiry-pln", bytes.NewBuffer(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
+	}
+	if result.Data.Name == "" {
+		return "", nickname.ErrAccountNotFound
+	}
+	return result.Data.Name, nil
+}
@@ -0,0 +1,124 @@
+package digiflazz
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	commandInquiryPostpaid = "inq-pasca"
+	commandPayPostpaid     = "pay-pasca"
+	commandStatusPostpaid  = "status-pasca"
+)
+
+// InquiryPostpaid asks for the open bill of a customer. The same ref_id must
+// be used to pay it.
+func (d *DigiflazzService) InquiryPostpaid(ctx context.Context, req CreateTransactionToDigiflazz) (*PostpaidResponse, error) {
+	return d.postpaid(ctx, commandInquiryPostpaid, req)
+}
+
+// PayPostpaid pays the bill inquired under req.RefID.
+func (d *DigiflazzService) PayPostpaid(ctx context.Context, req CreateTransactionToDigiflazz) (*PostpaidResponse, error) {
+	return d.postpaid(ctx, commandPayPostpaid, req)
+}
+
+// CheckPostpaidStatus reports the state of the payment made under req.RefID.
+func (d *DigiflazzService) CheckPostpaidStatus(ctx context.Context, req CreateTransactionToDigiflazz) (*PostpaidResponse, error) {
+	return d.postpaid(ctx, commandStatusPostpaid, req)
+}
+
+func (d *DigiflazzService) postpaid(ctx context.Context, command string, req CreateTransactionToDigiflazz) (*PostpaidResponse, error) {
+	hash := md5.Sum([]byte(d.config.DigiUsername + d.config.DigiKey + req.RefID))
+
+	requestPayload := map[string]interface{}{
+		"commands":       command,
+		"username":       d.config.DigiUsername,
+		"buyer_sku_code": req.BuyerSKUCode,
+		"customer_no":    req.CustomerNo,
+		"ref_id":         req.RefID,
+		"sign":           fmt.Sprintf("%x", hash),
+	}
+	if command == commandPayPostpaid && d.config.CallbackURL != "" {
+		requestPayload["cb_url"] = d.config.CallbackURL
+	}
+
+	jsonData, err := json.Marshal(requestPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.digiflazz.com/v1/transaction", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "DigiflazzClient/1.0")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResponse PostpaidResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
+	}
+	return &apiResponse, nil
+}
+
+// CheckPostpaidPrice fetches the postpaid price list.
+func (d *DigiflazzService) CheckPostpaidPrice(ctx context.Context) ([]PostpaidProductData, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"username": d.config.DigiUsername,
+		"cmd":      "pasca",
+		"sign":     d.generateSign(d.config.DigiUsername, d.config.DigiKey, "pricelist"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.digiflazz.com/v1/price-list", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResponse struct {
+		Data []PostpaidProductData `json:"data"`
+	}
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
+	}
+	return apiResponse.Data, nil
+}
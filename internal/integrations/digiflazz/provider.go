@@ -0,0 +1,216 @@
+package digiflazz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/integrations/provider"
+)
+
+// ProviderSlug is the providers.slug row for Digiflazz.
+const ProviderSlug = "digiflazz"
+
+var _ provider.PostpaidProvider = (*DigiflazzService)(nil)
+
+// postpaidType is the product type given to SKUs from the postpaid price list.
+const postpaidType = "Pascabayar"
+
+func (d *DigiflazzService) Slug() string {
+	return ProviderSlug
+}
+
+func (d *DigiflazzService) PriceList(ctx context.Context) ([]provider.Product, error) {
+	data, err := d.CheckPrice()
+	if err != nil {
+		return nil, err
+	}
+	postpaid, err := d.CheckPostpaidPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]provider.Product, 0, len(data)+len(postpaid))
+	for _, dp := range data {
+		products = append(products, provider.Product{
+			Code:        dp.BuyerSkuCode,
+			Name:        dp.ProductName,
+			Category:    dp.Category,
+			Brand:       dp.Brand,
+			Type:        dp.Type,
+			Description: dp.Desc,
+			SellerName:  dp.SellerName,
+			Price:       dp.Price,
+			Stock:       dp.Stock,
+			Unlimited:   dp.UnlimitedStock,
+			Active:      dp.BuyerProductStatus && dp.SellerProductStatus,
+			Multi:       dp.Multi,
+			StartCutOff: dp.StartCutOff,
+			EndCutOff:   dp.EndCutOff,
+		})
+	}
+	for _, dp := range postpaid {
+		products = append(products, provider.Product{
+			Code:        dp.BuyerSkuCode,
+			Name:        dp.ProductName,
+			Category:    dp.Category,
+			Brand:       dp.Brand,
+			Type:        postpaidType,
+			Description: dp.Desc,
+			SellerName:  dp.SellerName,
+			Price:       max(dp.Admin-dp.Commission, 0),
+			Unlimited:   true,
+			Active:      dp.BuyerProductStatus && dp.SellerProductStatus,
+			Postpaid:    true,
+		})
+	}
+	return products, nil
+}
+
+func (d *DigiflazzService) Purchase(ctx context.Context, req provider.PurchaseRequest) (*provider.PurchaseResult, error) {
+	if req.Postpaid {
+		resp, err := d.PayPostpaid(ctx, toPostpaidRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		return postpaidPurchaseResult(resp), nil
+	}
+
+	resp, err := d.TopUp(ctx, CreateTransactionToDigiflazz{
+		BuyerSKUCode: req.SKU,
+		CustomerNo:   req.CustomerNo,
+		RefID:        req.RefID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toPurchaseResult(resp), nil
+}
+
+// CheckStatus re-posts the original transaction. Digiflazz treats a repeated
+// ref_id as a status inquiry and never charges it twice. Bill payments have
+// their own status command.
+func (d *DigiflazzService) CheckStatus(ctx context.Context, req provider.PurchaseRequest) (*provider.PurchaseResult, error) {
+	if req.Postpaid {
+		resp, err := d.CheckPostpaidStatus(ctx, toPostpaidRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		return postpaidPurchaseResult(resp), nil
+	}
+	return d.Purchase(ctx, req)
+}
+
+// Inquiry fetches the open bill for req.CustomerNo. The bill total is the sum
+// of its periods, or the selling price less the admin fee when Digiflazz does
+// not itemise it.
+func (d *DigiflazzService) Inquiry(ctx context.Context, req provider.PurchaseRequest) (*provider.Bill, error) {
+	resp, err := d.InquiryPostpaid(ctx, toPostpaidRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	bill := &provider.Bill{
+		RefID:          resp.Data.RefID,
+		CustomerNo:     resp.Data.CustomerNo,
+		CustomerName:   resp.Data.CustomerName,
+		Price:          resp.Data.Price,
+		Status:         resp.Data.Status,
+		RC:             resp.Data.RC,
+		Message:        resp.Data.Message,
+		BuyerLastSaldo: resp.Data.BuyerLastSaldo,
+	}
+	details := resp.BillDetails()
+	if len(details) == 0 {
+		bill.Amount = resp.Data.SellingPrice - resp.Data.Admin
+		return bill, nil
+	}
+
+	periods := make([]string, 0, len(details))
+	for _, detail := range details {
+		bill.Amount += detail.NilaiTagihan + detail.Denda
+		periods = append(periods, detail.Periode)
+	}
+	bill.Period = strings.Join(periods, ", ")
+	return bill, nil
+}
+
+// Balance returns the deposit left at Digiflazz.
+func (d *DigiflazzService) Balance(ctx context.Context) (int, error) {
+	payload, err := json.Marshal(map[string]string{
+		"cmd":      "deposit",
+		"username": d.config.DigiUsername,
+		"sign":     d.generateSign(d.config.DigiUsername, d.config.DigiKey, "depo"),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.digiflazz.com/v1/cek-saldo", bytes.NewBuffer(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			Deposit float64 `json:"deposit"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
+	}
+	return int(result.Data.Deposit), nil
+}
+
+func toPostpaidRequest(req provider.PurchaseRequest) CreateTransactionToDigiflazz {
+	return CreateTransactionToDigiflazz{
+		BuyerSKUCode: req.SKU,
+		CustomerNo:   req.CustomerNo,
+		RefID:        req.RefID,
+	}
+}
+
+func postpaidPurchaseResult(resp *PostpaidResponse) *provider.PurchaseResult {
+	return &provider.PurchaseResult{
+		RefID:          resp.Data.RefID,
+		Status:         resp.Data.Status,
+		RC:             resp.Data.RC,
+		SN:             resp.Data.SN,
+		Message:        resp.Data.Message,
+		Price:          resp.Data.Price,
+		BuyerLastSaldo: resp.Data.BuyerLastSaldo,
+	}
+}
+
+func toPurchaseResult(resp *TransactionCreateDigiflazzResponse) *provider.PurchaseResult {
+	return &provider.PurchaseResult{
+		RefID:          resp.Data.RefID,
+		Status:         resp.Data.Status,
+		RC:             resp.Data.RC,
+		SN:             resp.Data.SN,
+		Message:        resp.Data.Message,
+		Price:          resp.Data.Price,
+		BuyerLastSaldo: resp.Data.BuyerLastSaldo,
+	}
+}
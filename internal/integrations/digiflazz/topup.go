@@ -24,7 +24,9 @@ func (d *DigiflazzService) TopUp(ctx context.Context, req CreateTransactionToDig
 		"customer_no":    req.CustomerNo,
 		"ref_id":         req.RefID,
 		"sign":           sign,
-		"cb_url":         "https://e3abda9a4f73.ngrok-free.app/api/transactions/callback/digiflazz",
+	}
+	if d.config.CallbackURL != "" {
+		requestPayload["cb_url"] = d.config.CallbackURL
 	}
 
 	jsonData, err := json.Marshal(requestPayload)
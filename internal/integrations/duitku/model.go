@@ -12,6 +12,32 @@ type DuitkuCreateTransactionParams struct {
 	ReturnUrl       *string `json:"returnUrl,omitempty"`
 }
 
+// DuitkuCallbackParams is the form Duitku posts to callbackUrl once a payment settles.
+type DuitkuCallbackParams struct {
+	MerchantCode    string `form:"merchantCode"`
+	Amount          string `form:"amount"`
+	MerchantOrderId string `form:"merchantOrderId"`
+	ProductDetail   string `form:"productDetail"`
+	AdditionalParam string `form:"additionalParam"`
+	PaymentCode     string `form:"paymentCode"`
+	ResultCode      string `form:"resultCode"`
+	MerchantUserId  string `form:"merchantUserId"`
+	Reference       string `form:"reference"`
+	Signature       string `form:"signature"`
+	SettlementDate  string `form:"settlementDate"`
+}
+
+const (
+	CallbackResultSuccess = "00"
+	CallbackResultFailed  = "01"
+)
+
+const (
+	TransactionStatusSuccess  = "00"
+	TransactionStatusPending  = "01"
+	TransactionStatusCanceled = "02"
+)
+
 type ResponseFromDuitkuCheckTransaction struct {
 	Status int `json:"status"`
 	Data   struct {
@@ -3,6 +3,7 @@ package duitku
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -41,6 +42,7 @@ func (s *DuitkuService) CreateTransaction(ctx context.Context, params *DuitkuCre
 		"paymentMethod":   params.PaymentCode,
 		"signature":       signature,
 		"callbackUrl":     params.CallbackUrl,
+		"returnUrl":       params.ReturnUrl,
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -73,6 +75,63 @@ func (s *DuitkuService) CreateTransaction(ctx context.Context, params *DuitkuCre
 	return &duitkuResponse, nil
 }
 
+// CheckTransaction asks Duitku for the current status of a merchant order.
+func (s *DuitkuService) CheckTransaction(ctx context.Context, merchantOrderId string) (*ResponseFromDuitkuCheckTransaction, error) {
+	signature := md5Hex(s.DuitkuMerchantCode + merchantOrderId + s.DuitkuKey)
+
+	payload := map[string]interface{}{
+		"merchantCode":    s.DuitkuMerchantCode,
+		"merchantOrderId": merchantOrderId,
+		"signature":       signature,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.BaseUrlGetTransaction, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HttpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	result := &ResponseFromDuitkuCheckTransaction{Status: resp.StatusCode}
+	if err := json.Unmarshal(body, &result.Data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
+	}
+	return result, nil
+}
+
+// VerifyCallbackSignature checks md5(merchantCode + amount + merchantOrderId + apiKey).
+func (s *DuitkuService) VerifyCallbackSignature(params *DuitkuCallbackParams) bool {
+	if params.MerchantCode != s.DuitkuMerchantCode {
+		return false
+	}
+	expected := md5Hex(params.MerchantCode + params.Amount + params.MerchantOrderId + s.DuitkuKey)
+	return hmac.Equal([]byte(expected), []byte(params.Signature))
+}
+
+func md5Hex(data string) string {
+	hash := md5.Sum([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
 func (s *DuitkuService) generateSignature(merchantOrderId string, paymentAmount int) string {
 
 	signatureString := s.DuitkuMerchantCode + merchantOrderId + strconv.Itoa(paymentAmount) + s.DuitkuKey
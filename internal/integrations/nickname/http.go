@@ -0,0 +1,80 @@
+package nickname
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPChecker calls a third-party nickname API of the form
+// GET {baseURL}/{code}?id={userID}&zone={zoneID}, authenticated with an API key
+// in the X-API-Key header. Unknown games answer 404.
+type HTTPChecker struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewHTTPChecker(baseURL, apiKey string, timeout time.Duration) *HTTPChecker {
+	return &HTTPChecker{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTPChecker) Name() string {
+	return "http"
+}
+
+func (h *HTTPChecker) Check(ctx context.Context, req Request) (string, error) {
+	query := url.Values{}
+	query.Set("id", req.UserID)
+	if req.ZoneID != "" {
+		query.Set("zone", req.ZoneID)
+	}
+	endpoint := fmt.Sprintf("%s/%s?%s", h.baseURL, url.PathEscape(strings.ToLower(req.Code)), query.Encode())
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if h.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", h.apiKey)
+	}
+
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotSupported
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return "", fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Success bool   `json:"success"`
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w, body: %s", err, string(body))
+	}
+	if !result.Success || result.Name == "" {
+		return "", ErrAccountNotFound
+	}
+	return result.Name, nil
+}
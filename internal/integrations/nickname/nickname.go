@@ -0,0 +1,48 @@
+// Package nickname defines the contract for looking up the account name behind
+// a game ID or customer number, so customers can confirm it before paying.
+package nickname
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotSupported means the checker does not know the requested game.
+	ErrNotSupported = errors.New("game is not supported by this checker")
+	// ErrAccountNotFound means the checker knows the game but not the account.
+	ErrAccountNotFound = errors.New("account not found")
+)
+
+// Request identifies an account. Code and Brand come from the category; ZoneID
+// is empty for games without a server or zone.
+type Request struct {
+	Code   string
+	Brand  string
+	UserID string
+	ZoneID string
+}
+
+// Checker resolves the account name for a Request.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context, req Request) (string, error)
+}
+
+// Chain asks each checker in turn, skipping those that do not support the game.
+type Chain []Checker
+
+func (c Chain) Name() string {
+	return "chain"
+}
+
+func (c Chain) Check(ctx context.Context, req Request) (string, error) {
+	for _, checker := range c {
+		name, err := checker.Check(ctx, req)
+		if errors.Is(err, ErrNotSupported) {
+			continue
+		}
+		return name, err
+	}
+	return "", ErrNotSupported
+}
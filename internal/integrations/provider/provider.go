@@ -0,0 +1,99 @@
+// Package provider defines the contract every top-up supplier integration
+// implements, so orders can be routed between suppliers.
+package provider
+
+import "context"
+
+// Provider is a top-up supplier. Statuses use the transaction vocabulary:
+// model.TransactionStatusPending, Success or Failed.
+type Provider interface {
+	// Slug matches providers.slug in the database.
+	Slug() string
+	PriceList(ctx context.Context) ([]Product, error)
+	Purchase(ctx context.Context, req PurchaseRequest) (*PurchaseResult, error)
+	// CheckStatus reports the current state of an earlier purchase. It must be
+	// safe to call for a purchase whose request may never have arrived.
+	CheckStatus(ctx context.Context, req PurchaseRequest) (*PurchaseResult, error)
+	Balance(ctx context.Context) (int, error)
+}
+
+type Product struct {
+	Code        string
+	Name        string
+	Category    string
+	Brand       string
+	Type        string
+	Description string
+	SellerName  string
+	Price       int
+	Stock       int
+	Unlimited   bool
+	Active      bool
+	Multi       bool
+	StartCutOff string
+	EndCutOff   string
+	// Postpaid SKUs are paid after an Inquiry; Price is then the fee the
+	// provider lists for a single billing period. The fee actually charged
+	// comes from each Inquiry.
+	Postpaid bool
+}
+
+// PostpaidProvider is a Provider that also settles postpaid bills. A bill is
+// inquired under a RefID and later paid by a Purchase with the same RefID and
+// Postpaid set.
+type PostpaidProvider interface {
+	Provider
+	Inquiry(ctx context.Context, req PurchaseRequest) (*Bill, error)
+}
+
+// PurchaseRequest identifies one purchase at the provider. RefID is the
+// provider-side reference and must be unique per attempt. Postpaid requests
+// pay or check the bill inquired under RefID.
+type PurchaseRequest struct {
+	SKU        string
+	CustomerNo string
+	RefID      string
+	Postpaid   bool
+}
+
+type PurchaseResult struct {
+	RefID          string
+	Status         string
+	RC             string
+	SN             string
+	Message        string
+	Price          int
+	BuyerLastSaldo *int
+}
+
+// Bill is the answer to a postpaid inquiry. Amount is what the customer owes
+// the biller, penalties included; Price is what the provider charges us to
+// pay it.
+type Bill struct {
+	RefID          string
+	CustomerNo     string
+	CustomerName   string
+	Period         string
+	Amount         int
+	Price          int
+	Status         string
+	RC             string
+	Message        string
+	BuyerLastSaldo *int
+}
+
+// Registry looks providers up by slug.
+type Registry map[string]Provider
+
+func NewRegistry(providers ...Provider) Registry {
+	registry := make(Registry, len(providers))
+	for _, p := range providers {
+		registry[p.Slug()] = p
+	}
+	return registry
+}
+
+func (r Registry) Get(slug string) (Provider, bool) {
+	p, ok := r[slug]
+	return p, ok
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/response"
+	"github.com/wafi04/otomaxv2/pkg/token"
+)
+
+// AuthMiddleware resolves the bearer access token to a user and stores the
+// user and role on the gin context under model.UserContextKey and
+// model.RoleContextKey.
+type AuthMiddleware struct {
+	tokens *token.Manager
+	users  *repository.AuthRepository
+}
+
+func NewAuthMiddleware(tokens *token.Manager, users *repository.AuthRepository) *AuthMiddleware {
+	return &AuthMiddleware{
+		tokens: tokens,
+		users:  users,
+	}
+}
+
+// Authenticate rejects requests without a valid access token.
+func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.resolve(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Optional identifies the caller when a token is sent but lets anonymous
+// requests through, e.g. so public listings can show role prices.
+func (m *AuthMiddleware) Optional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if bearerToken(c) != "" && !m.resolve(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole authenticates the caller and allows only the given roles.
+func (m *AuthMiddleware) RequireRole(roles ...model.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.resolve(c) {
+			return
+		}
+		role, _ := c.Get(model.RoleContextKey)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		response.ErrorResponse(c, http.StatusForbidden, "Forbidden", "insufficient role")
+		c.Abort()
+	}
+}
+
+// resolve loads the user behind the bearer token. The role is read from the
+// database rather than the token so demotions take effect immediately.
+func (m *AuthMiddleware) resolve(c *gin.Context) bool {
+	if _, ok := c.Get(model.UserContextKey); ok {
+		return true
+	}
+
+	raw := bearerToken(c)
+	if raw == "" {
+		unauthorized(c, "missing bearer token")
+		return false
+	}
+
+	claims, err := m.tokens.Parse(raw, token.TypeAccess)
+	if err != nil {
+		unauthorized(c, err.Error())
+		return false
+	}
+
+	user, err := m.users.GetUserByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to load user", err.Error())
+		c.Abort()
+		return false
+	}
+	if user == nil || user.Status != model.UserStatusActive {
+		unauthorized(c, "user is not active")
+		return false
+	}
+
+	user.Role = model.ParseUserRole(string(user.Role))
+	c.Set(model.UserContextKey, user)
+	c.Set(model.RoleContextKey, user.Role)
+	return true
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+func unauthorized(c *gin.Context, detail string) {
+	response.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized", detail)
+	c.Abort()
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+const (
+	IdempotencyKeyHeader        = "Idempotency-Key"
+	IdempotentReplayedHeader    = "Idempotent-Replayed"
+	maxIdempotencyKeyLength     = 255
+	idempotencyGuestOwnerPrefix = "guest:"
+	idempotencyCompleteAttempts = 3
+)
+
+// IdempotencyMiddleware makes create endpoints safe to retry. A request sent
+// with an Idempotency-Key header runs once per caller; repeats within the TTL
+// get the stored response, and reusing the key for a different body is
+// rejected.
+type IdempotencyMiddleware struct {
+	keys *repository.IdempotencyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotencyMiddleware(keys *repository.IdempotencyRepository, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		keys: keys,
+		ttl:  ttl,
+	}
+}
+
+// Handle guards one endpoint; scope keeps keys of different endpoints apart.
+// It must run after the auth middleware so keys are owned by the caller.
+func (m *IdempotencyMiddleware) Handle(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.ErrorResponse(c, http.StatusBadRequest, "Invalid Idempotency-Key", "key must be at most 255 characters")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err.Error())
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+
+		record, claimed, err := m.keys.Begin(c.Request.Context(), &model.IdempotencyKey{
+			Scope:       scope,
+			Owner:       idempotencyOwner(c),
+			Key:         key,
+			RequestHash: hex.EncodeToString(hash[:]),
+			ExpiresAt:   time.Now().Add(m.ttl),
+		})
+		if err != nil {
+			response.ErrorResponse(c, http.StatusInternalServerError, "Failed to check Idempotency-Key", err.Error())
+			c.Abort()
+			return
+		}
+		if !claimed {
+			replay(c, record, hex.EncodeToString(hash[:]))
+			return
+		}
+
+		m.run(c, record.ID)
+	}
+}
+
+// run executes the handler and stores whatever it answered, errors included,
+// since the request may have charged the caller before failing. Only a
+// request that ended without a response frees the key. When the response
+// cannot be stored the key stays IN_PROGRESS until it expires, so retries get
+// 409 instead of running the request again.
+func (m *IdempotencyMiddleware) run(c *gin.Context, id int) {
+	// the outcome must be saved even if the client hung up meanwhile
+	ctx := context.WithoutCancel(c.Request.Context())
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+
+	defer func() {
+		if !recorder.Written() {
+			if err := m.keys.Release(ctx, id); err != nil {
+				log.Printf("Failed to release idempotency key %d: %v", id, err)
+			}
+		}
+	}()
+
+	c.Next()
+
+	if !recorder.Written() {
+		return
+	}
+	for attempt := 1; ; attempt++ {
+		err := m.keys.Complete(ctx, id, recorder.Status(), recorder.body.String())
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to store idempotent response %d (attempt %d): %v", id, attempt, err)
+		if attempt == idempotencyCompleteAttempts {
+			log.Printf("Idempotency key %d left in progress after its response was sent", id)
+			return
+		}
+		time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+	}
+}
+
+func replay(c *gin.Context, record *model.IdempotencyKey, requestHash string) {
+	switch {
+	case record.RequestHash != requestHash:
+		response.ErrorResponse(c, http.StatusUnprocessableEntity, "Idempotency-Key reused",
+			"the key was already used with a different request")
+	case record.Status != model.IdempotencyStatusCompleted || record.ResponseStatus == nil || record.ResponseBody == nil:
+		response.ErrorResponse(c, http.StatusConflict, "Request in progress",
+			"a request with this Idempotency-Key is still being processed")
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(*record.ResponseStatus, "application/json; charset=utf-8", []byte(*record.ResponseBody))
+	}
+	c.Abort()
+}
+
+// idempotencyOwner scopes keys to the signed-in user, or to the client IP for
+// guest checkouts. ClientIP only honours forwarding headers from the proxies
+// in SERVER_TRUSTED_PROXIES, so guests cannot pick another guest's owner.
+func idempotencyOwner(c *gin.Context) string {
+	if value, ok := c.Get(model.UserContextKey); ok {
+		if user, ok := value.(*model.UserData); ok && user != nil {
+			return user.Username
+		}
+	}
+	return idempotencyGuestOwnerPrefix + c.ClientIP()
+}
+
+// responseRecorder keeps a copy of the body written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/response"
+)
+
+// RateLimit answers 429 once the client IP has used up the limiter's attempts;
+// every request counts as one. Without Redis requests are let through, as the
+// endpoints it guards are conveniences rather than security checks.
+func RateLimit(limiter *services.AttemptLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		blocked, err := limiter.Blocked(c.Request.Context(), ip)
+		if err == nil && !blocked {
+			_, err = limiter.Hit(c.Request.Context(), ip)
+		}
+		if err != nil && !errors.Is(err, services.ErrLimiterUnavailable) {
+			log.Printf("Rate limiter for %s failed: %v", ip, err)
+		}
+		if blocked {
+			response.ErrorResponse(c, http.StatusTooManyRequests, "Too many requests", "please wait before trying again")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -13,17 +13,65 @@ type UserData struct {
 	AvatarUrl  *string `json:"avatarUrl"`
 	PhoneVerifiedAt  *time.Time `json:"PhoneVerified"`
 	Status string  `json:"status"`
+	Role  UserRole  `json:"role"`
+	Balance  int64  `json:"balance"`
 	CreatedAt  time.Time `json:"createdAt"`
 	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
 
 
+const UserStatusActive = "active"
+
 type GoogleCallback struct {
+	ID     string `json:"id"`
 	Email  string `json:"email"`
     FamilyName string `json:"family_name"`
     GiveName  string `json:"given_name"`
     Name   string `json:"name"`
     Picture string `json:"picture"`
-	VerifiedEmail string `json:"verified_email"`
-}
\ No newline at end of file
+	VerifiedEmail bool `json:"verified_email"`
+}
+
+// AccountLink is what a Google sign-in needs to know about the account that
+// already owns its email address.
+type AccountLink struct {
+	User          *UserData
+	GoogleID      string
+	HasPassword   bool
+	EmailVerified bool
+}
+
+type AuthTokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+}
+
+type LoginResponse struct {
+	User   *UserData   `json:"user"`
+	Tokens *AuthTokens `json:"tokens"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+type RegisterRequest struct {
+	Username  string `json:"username" validate:"required,min=3,max=30,username"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,password"`
+	Phone     string `json:"phone" validate:"required,phone"`
+	FirstName string `json:"firstName" validate:"required"`
+	LastName  string `json:"lastName"`
+}
+
+// LoginRequest accepts either the username or the email as Identifier.
+type LoginRequest struct {
+	Identifier string `json:"identifier" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,numeric"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+const (
+	BillStatusOpen    = "OPEN"
+	BillStatusOrdered = "ORDERED"
+)
+
+// Bill is an inquired postpaid bill. Its Total, the bill Amount plus our
+// AdminFee, stays locked until ExpiresAt; paying it creates one transaction.
+type Bill struct {
+	ID                int       `json:"id"`
+	RefID             string    `json:"refId"`
+	Username          string    `json:"username"`
+	ProductID         int       `json:"productId"`
+	ProductName       string    `json:"productName"`
+	ProviderProductID int       `json:"-"`
+	ProviderCode      string    `json:"-"`
+	ProviderSlug      string    `json:"-"`
+	CustomerNo        string    `json:"customerNo"`
+	CustomerName      string    `json:"customerName"`
+	Period            string    `json:"period"`
+	Amount            int       `json:"amount"`
+	AdminFee          int       `json:"adminFee"`
+	CostPrice         int       `json:"-"`
+	Total             int       `json:"total"`
+	Status            string    `json:"status"`
+	TransactionRefID  *string   `json:"transactionRefId,omitempty"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+type CreateBillInquiry struct {
+	ProductID  int      `json:"productId" binding:"required"`
+	CustomerNo string   `json:"customerNo" binding:"required"`
+	Username   string   `json:"-"`
+	Role       UserRole `json:"-"`
+}
+
+type PayBill struct {
+	Method   string   `json:"method" binding:"required"`
+	Username string   `json:"-"`
+	Role     UserRole `json:"-"`
+}
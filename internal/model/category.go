@@ -15,8 +15,11 @@ type Category struct {
 	Information     *string `json:"information,omitempty"`
 	Placeholder1    string  `json:"placeholder1"`
 	Placeholder2    *string `json:"placeholder2,omitempty"`
-	CreatedAt       string  `json:"createdAt"`
-	UpdatedAt       string  `json:"updatedAt"`
+	// DuplicateWindowMinutes overrides the default window in which an identical
+	// order is refused; 0 turns the check off for the category.
+	DuplicateWindowMinutes *int   `json:"duplicateWindowMinutes,omitempty"`
+	CreatedAt              string `json:"createdAt"`
+	UpdatedAt              string `json:"updatedAt"`
 }
 
 type Product struct {
@@ -26,6 +29,7 @@ type Product struct {
 	DenominationType string  `json:"denominationType"`
 	SubCategoryName  *string `json:"subCategoryName,omitempty"`
 	SubCategoryID    *int    `json:"subCategoryID,omitempty"`
+	InCutOff         bool    `json:"inCutOff"`
 }
 type CategoryCodeResponse struct {
 	ID              int       `json:"id"`
@@ -45,19 +49,20 @@ type CategoryCodeResponse struct {
 }
 
 type CreateCategory struct {
-	Name            string  `json:"name"`
-	SubName         string  `json:"subName"`
-	Brand           string  `json:"brand"`
-	Code            string  `json:"code"`
-	IsCheckNickname string  `json:"isCheckNickname"`
-	Status          string  `json:"status"`
-	Thumbnail       string  `json:"thumbnail"`
-	Type            string  `json:"type"`
-	Banner          string  `json:"banner"`
-	Placeholder1    string  `json:"placeholder1"`
-	Placeholder2    *string `json:"placeholder2,omitempty"`
-	Instruction     *string `json:"instruction,omitempty"`
-	Information     string  `json:"information"`
+	Name                   string  `json:"name"`
+	SubName                string  `json:"subName"`
+	Brand                  string  `json:"brand"`
+	Code                   string  `json:"code"`
+	IsCheckNickname        string  `json:"isCheckNickname"`
+	Status                 string  `json:"status"`
+	Thumbnail              string  `json:"thumbnail"`
+	Type                   string  `json:"type"`
+	Banner                 string  `json:"banner"`
+	Placeholder1           string  `json:"placeholder1"`
+	Placeholder2           *string `json:"placeholder2,omitempty"`
+	Instruction            *string `json:"instruction,omitempty"`
+	DuplicateWindowMinutes *int    `json:"duplicateWindowMinutes,omitempty" binding:"omitempty,min=0"`
+	Information            string  `json:"information"`
 }
 
 type UpdateCategory struct {
@@ -72,3 +77,17 @@ type UpdateCategory struct {
 	Instruction     *string `json:"instruction"`
 	Information     *string `json:"information"`
 }
+
+// CheckNicknameRequest identifies a game account. ZoneID is required for
+// categories with a second placeholder, such as a server or zone ID.
+type CheckNicknameRequest struct {
+	UserID string `json:"userId" binding:"required"`
+	ZoneID string `json:"zoneId"`
+}
+
+type NicknameResult struct {
+	CategoryCode string `json:"categoryCode"`
+	UserID       string `json:"userId"`
+	ZoneID       string `json:"zoneId,omitempty"`
+	Nickname     string `json:"nickname"`
+}
@@ -2,19 +2,28 @@ package model
 
 import "time"
 
+const (
+	DepositStatusPending = "PENDING"
+	DepositStatusPaid    = "PAID"
+	DepositStatusFailed  = "FAILED"
+)
+
 type DepositData struct {
 	ID                int       `json:"id"`
+	DepositID         string    `json:"depositId"`
 	Username          string    `json:"username"`
 	Method            string    `json:"method"`
 	PaymentReferee    *string   `json:"paymentReferee,omitempty"`
 	DestinationNumber string    `json:"destinationNumber"`
 	Amount            int       `json:"amount"`
 	Status            string    `json:"status"`
+	StatusChecks      int       `json:"statusChecks,omitempty"`
 	CreatedAt         time.Time `json:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt"`
 }
 
 type CreateDeposit struct {
+	DepositID         string  `json:"depositId"`
 	Amount            int     `json:"amount"`
 	Method            string  `json:"method"`
 	Username          string  `json:"username"`
@@ -23,8 +32,8 @@ type CreateDeposit struct {
 }
 
 type RequestFormClient struct {
-	Amount int    `json:"amount"`
-	Method string `json:"method"`
+	Amount int    `json:"amount" binding:"required,min=1"`
+	Method string `json:"method" binding:"required"`
 }
 type FilterDeposit struct {
 	Search *string `json:"search,omitempty"`
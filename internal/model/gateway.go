@@ -0,0 +1,30 @@
+package model
+
+// GatewayMessage is one inbound text command relayed from a chat or SMS
+// channel. Sender is the phone number the message came from.
+type GatewayMessage struct {
+	Sender  string `json:"sender" binding:"required"`
+	Message string `json:"message" binding:"required"`
+}
+
+type GatewayReply struct {
+	Reply string `json:"reply"`
+}
+
+// KeywordProduct is a product as the text gateway sees it.
+type KeywordProduct struct {
+	ProductID int
+	Keyword   string
+	Name      string
+	Price     int
+	Available bool
+}
+
+type SetPINRequest struct {
+	PIN        string `json:"pin" validate:"required,numeric,min=4,max=6"`
+	CurrentPIN string `json:"currentPin"`
+}
+
+type SetProductKeyword struct {
+	Keyword string `json:"keyword"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	IdempotencyStatusInProgress = "IN_PROGRESS"
+	IdempotencyStatusCompleted  = "COMPLETED"
+)
+
+// IdempotencyKey records the first request made with a client-supplied key
+// and, once it finished, the response to replay for repeats.
+type IdempotencyKey struct {
+	ID             int
+	Scope          string
+	Owner          string
+	Key            string
+	RequestHash    string
+	Status         string
+	ResponseStatus *int
+	ResponseBody   *string
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
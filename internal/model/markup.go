@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+const (
+	MarkupTypeFixed      = "FIXED"
+	MarkupTypePercentage = "PERCENTAGE"
+)
+
+// MarkupRule prices provider SKUs for one role. Nil match fields match any
+// value; among matching active rules the highest priority wins.
+type MarkupRule struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Role        UserRole  `json:"role"`
+	Provider    *string   `json:"provider,omitempty"`
+	Category    *string   `json:"category,omitempty"`
+	Brand       *string   `json:"brand,omitempty"`
+	Type        *string   `json:"type,omitempty"`
+	MinCost     *int      `json:"minCost,omitempty"`
+	MaxCost     *int      `json:"maxCost,omitempty"`
+	MarkupType  string    `json:"markupType"`
+	MarkupValue float64   `json:"markupValue"`
+	MinProfit   int       `json:"minProfit"`
+	Rounding    int       `json:"rounding"`
+	Priority    int       `json:"priority"`
+	IsActive    bool      `json:"isActive"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type CreateMarkupRule struct {
+	Name        string   `json:"name" binding:"required"`
+	Role        UserRole `json:"role"`
+	Provider    *string  `json:"provider,omitempty"`
+	Category    *string  `json:"category,omitempty"`
+	Brand       *string  `json:"brand,omitempty"`
+	Type        *string  `json:"type,omitempty"`
+	MinCost     *int     `json:"minCost,omitempty"`
+	MaxCost     *int     `json:"maxCost,omitempty"`
+	MarkupType  string   `json:"markupType" binding:"required"`
+	MarkupValue float64  `json:"markupValue" binding:"min=0"`
+	MinProfit   int      `json:"minProfit" binding:"min=0"`
+	Rounding    int      `json:"rounding"`
+	Priority    int      `json:"priority"`
+	IsActive    *bool    `json:"isActive,omitempty"`
+}
+
+// PricingInput describes the provider SKU being priced.
+type PricingInput struct {
+	Provider  string `json:"provider"`
+	Category  string `json:"category"`
+	Brand     string `json:"brand"`
+	Type      string `json:"type"`
+	CostPrice int    `json:"costPrice" binding:"required,min=1"`
+}
+
+// PricePreviewRequest prices a SKU against the active rules, optionally with a
+// draft rule added as if it were already saved.
+type PricePreviewRequest struct {
+	PricingInput
+	Rule *CreateMarkupRule `json:"rule,omitempty"`
+}
+
+type RolePrice struct {
+	Role     UserRole `json:"role"`
+	Price    int      `json:"price"`
+	Profit   int      `json:"profit"`
+	RuleID   *int     `json:"ruleId,omitempty"`
+	RuleName string   `json:"ruleName,omitempty"`
+}
+
+type PricePreview struct {
+	CostPrice int         `json:"costPrice"`
+	Prices    []RolePrice `json:"prices"`
+}
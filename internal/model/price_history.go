@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+const (
+	PriceAlertNegativeMargin = "NEGATIVE_MARGIN"
+	PriceAlertPriceJump      = "PRICE_JUMP"
+)
+
+// PriceHistory is one price change of a provider SKU. The old prices are nil
+// for the SKU's first sync. AlertType is set when the change needs review.
+type PriceHistory struct {
+	ID                int       `json:"id"`
+	ProviderProductID int       `json:"providerProductId"`
+	ProviderCode      string    `json:"providerCode"`
+	ProductID         int       `json:"productId"`
+	ProductName       string    `json:"productName"`
+	SyncRunID         *int      `json:"syncRunId,omitempty"`
+	OldCostPrice      *int      `json:"oldCostPrice"`
+	NewCostPrice      int       `json:"newCostPrice"`
+	OldSellingPrice   *int      `json:"oldSellingPrice"`
+	NewSellingPrice   int       `json:"newSellingPrice"`
+	ChangePercent     *float64  `json:"changePercent"`
+	AlertType         *string   `json:"alertType,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
+
+type FilterPriceAlert struct {
+	Type      string `json:"type"`
+	SyncRunID int    `json:"syncRunId"`
+	Limit     int    `json:"limit"`
+	Offset    int    `json:"offset"`
+}
@@ -21,4 +21,7 @@ type Provider struct {
     Stock         int `json:"stock"`
     IsAvailable   bool `json:"isAvailable"`
     IsMaintenance bool `json:"isMaintenance"`
+    StartCutOff   *string `json:"startCutOff"`
+    EndCutOff     *string `json:"endCutOff"`
+    InCutOff      bool `json:"inCutOff"`
 }
@@ -0,0 +1,89 @@
+package model
+
+import "time"
+
+// ResellerKey grants a user host-to-host (H2H) access. Requests are signed
+// with md5(username + APIKey + subject) the way Digiflazz signs ours, and are
+// only accepted from IPWhitelist; a key with an empty whitelist is unusable.
+type ResellerKey struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"userId"`
+	Username    string    `json:"username"`
+	Role        UserRole  `json:"-"`
+	APIKey      string    `json:"-"`
+	IPWhitelist []string  `json:"ipWhitelist"`
+	CallbackURL *string   `json:"callbackUrl,omitempty"`
+	IsActive    bool      `json:"isActive"`
+	RotatedAt   time.Time `json:"rotatedAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// IssuedResellerKey is returned once when a key is issued or rotated.
+type IssuedResellerKey struct {
+	APIKey   string       `json:"apiKey"`
+	Settings *ResellerKey `json:"settings"`
+}
+
+type UpdateResellerSettings struct {
+	IPWhitelist []string `json:"ipWhitelist"`
+	CallbackURL *string  `json:"callbackUrl"`
+}
+
+// H2HRequest is the body of every reseller API call. Which fields are needed
+// depends on the endpoint; Sign always covers Username and the API key.
+type H2HRequest struct {
+	Username   string `json:"username" binding:"required"`
+	Sign       string `json:"sign" binding:"required"`
+	ProductID  int    `json:"product_id"`
+	CustomerNo string `json:"customer_no"`
+	RefID      string `json:"ref_id"`
+	// Repeat confirms an identical recent purchase as intentional.
+	Repeat int `json:"repeat"`
+}
+
+// H2HProduct is one row of the reseller price list.
+type H2HProduct struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"product_name"`
+	Category  string `json:"category"`
+	Price     int    `json:"price"`
+	Available bool   `json:"available"`
+}
+
+// H2HTransaction reports an order to a reseller, in the field names resellers
+// know from Digiflazz.
+type H2HTransaction struct {
+	RefID      string `json:"ref_id"`
+	TrxID      string `json:"trx_id"`
+	ProductID  int    `json:"product_id"`
+	CustomerNo string `json:"customer_no"`
+	Price      int    `json:"price"`
+	Status     string `json:"status"`
+	RC         string `json:"rc"`
+	SN         string `json:"sn"`
+	Message    string `json:"message"`
+}
+
+func NewH2HTransaction(trx *Transaction) H2HTransaction {
+	out := H2HTransaction{
+		TrxID:      trx.RefID,
+		ProductID:  trx.ProductID,
+		CustomerNo: trx.CustomerNo,
+		Price:      trx.Total,
+		Status:     trx.Status,
+	}
+	if trx.ResellerRefID != nil {
+		out.RefID = *trx.ResellerRefID
+	}
+	if trx.RC != nil {
+		out.RC = *trx.RC
+	}
+	if trx.SN != nil {
+		out.SN = *trx.SN
+	}
+	if trx.Message != nil {
+		out.Message = *trx.Message
+	}
+	return out
+}
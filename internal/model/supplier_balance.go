@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	SupplierBalanceSourceCheck    = "CHECK"
+	SupplierBalanceSourcePurchase = "PURCHASE"
+	SupplierBalanceSourceCallback = "CALLBACK"
+)
+
+// SupplierBalance is a snapshot of our deposit at a provider. Reference is the
+// provider ref_id for snapshots taken from a purchase or callback.
+type SupplierBalance struct {
+	ID        int       `json:"id"`
+	Provider  string    `json:"provider"`
+	Balance   int64     `json:"balance"`
+	Source    string    `json:"source"`
+	Reference *string   `json:"reference,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type FilterSupplierBalance struct {
+	Provider string `json:"provider"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+const (
+	SyncTriggerSchedule = "schedule"
+	SyncTriggerManual   = "manual"
+
+	SyncStatusRunning = "running"
+	SyncStatusSuccess = "success"
+	SyncStatusFailed  = "failed"
+)
+
+// SyncCounts tallies what a product sync did with each provider SKU.
+type SyncCounts struct {
+	Inserted int `json:"inserted"`
+	Updated  int `json:"updated"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
+	Removed  int `json:"removed"`
+}
+
+// SyncFailure is a price list row the sync could not apply.
+type SyncFailure struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// SyncRemoval is a provider SKU that was missing from the price list and has
+// been marked unavailable.
+type SyncRemoval struct {
+	Code        string `json:"code"`
+	ProductID   int    `json:"productId"`
+	ProductName string `json:"productName"`
+}
+
+// SyncReport is the outcome of applying one price list.
+type SyncReport struct {
+	SyncCounts
+	Failures []SyncFailure `json:"failures"`
+	Removals []SyncRemoval `json:"removals"`
+}
+
+// SyncRun is one product sync against a provider price list.
+type SyncRun struct {
+	ID       int    `json:"id"`
+	Provider string `json:"provider"`
+	Trigger  string `json:"trigger"`
+	Status   string `json:"status"`
+	SyncReport
+	Error      *string    `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,125 @@
+package model
+
+import "time"
+
+const (
+	TransactionStatusPending = "Pending"
+	TransactionStatusSuccess = "Sukses"
+	TransactionStatusFailed  = "Gagal"
+)
+
+// PaymentMethodSaldo pays an order from the member's wallet balance; any other
+// method is a payment_methods code handled by the payment gateway.
+const PaymentMethodSaldo = "SALDO"
+
+const (
+	PaymentStatusUnpaid   = "UNPAID"
+	PaymentStatusPaid     = "PAID"
+	PaymentStatusFailed   = "FAILED"
+	PaymentStatusRefunded = "REFUNDED"
+)
+
+type Transaction struct {
+	ID                int        `json:"id"`
+	RefID             string     `json:"refId"`
+	Username          string     `json:"username"`
+	ProductID         int        `json:"productId"`
+	ProductName       string     `json:"productName"`
+	ProviderProductID int        `json:"-"`
+	ProviderCode      string     `json:"providerCode"`
+	ProviderSlug      string     `json:"-"`
+	ProviderRefID     *string    `json:"-"`
+	CustomerNo        string     `json:"customerNo"`
+	Price             int        `json:"price"`
+	CostPrice         int        `json:"-"`
+	Fee               int        `json:"fee"`
+	Total             int        `json:"total"`
+	PaymentMethod     string     `json:"paymentMethod"`
+	PaymentStatus     string     `json:"paymentStatus"`
+	PaymentReference  *string    `json:"paymentReference,omitempty"`
+	PaymentUrl        *string    `json:"paymentUrl,omitempty"`
+	Status            string     `json:"status"`
+	RC                *string    `json:"rc,omitempty"`
+	SN                *string    `json:"sn,omitempty"`
+	Message           *string    `json:"message,omitempty"`
+	BuyerLastSaldo    *int       `json:"-"`
+	StatusChecks      int        `json:"statusChecks,omitempty"`
+	EscalatedAt       *time.Time `json:"escalatedAt,omitempty"`
+	EscalationReason  *string    `json:"escalationReason,omitempty"`
+	BillRefID         *string    `json:"billRefId,omitempty"`
+	ResellerRefID     *string    `json:"resellerRefId,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+type CreateTransaction struct {
+	ProductID  int      `json:"productId" binding:"required"`
+	CustomerNo string   `json:"customerNo" binding:"required"`
+	ZoneID     *string  `json:"zoneId,omitempty"`
+	Method     string   `json:"method" binding:"required"`
+	Username   string   `json:"-"`
+	Role       UserRole `json:"-"`
+	// ResellerRefID is the reseller's own reference for H2H orders.
+	ResellerRefID *string `json:"-"`
+	// Repeat confirms an intentional repeat of an identical recent order: the
+	// Nth identical order in the duplicate window needs Repeat of at least N.
+	Repeat int `json:"repeat,omitempty" binding:"omitempty,min=0"`
+}
+
+// CutOffTimeZone is the zone provider cut-off windows are expressed in.
+const CutOffTimeZone = "Asia/Jakarta"
+
+// OrderProduct is the priced product and the provider SKU chosen to fulfil it.
+type OrderProduct struct {
+	ProductID         int
+	ProductName       string
+	Price             int
+	ProviderProductID int
+	ProviderCode      string
+	ProviderSlug      string
+	CostPrice         int
+	Postpaid          bool
+	InCutOff          bool
+	// DuplicateWindowMinutes is nil when the category uses the default window.
+	DuplicateWindowMinutes *int
+}
+
+// ProviderCandidate is one provider SKU able to fulfil a product.
+type ProviderCandidate struct {
+	ProviderProductID int
+	ProviderCode      string
+	ProviderSlug      string
+	CostPrice         int
+}
+
+// TransactionAttempt is one purchase sent to a provider for an order.
+type TransactionAttempt struct {
+	ID                int       `json:"id"`
+	RefID             string    `json:"refId"`
+	ProviderRefID     string    `json:"providerRefId"`
+	ProviderSlug      string    `json:"providerSlug"`
+	ProviderProductID int       `json:"providerProductId"`
+	ProviderCode      string    `json:"providerCode"`
+	CostPrice         int       `json:"costPrice"`
+	Status            string    `json:"status"`
+	RC                *string   `json:"rc,omitempty"`
+	Message           *string   `json:"message,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+type TransactionProviderResult struct {
+	Status         string
+	RC             string
+	SN             string
+	Message        string
+	BuyerLastSaldo *int
+}
+
+type FilterTransaction struct {
+	Search   string `json:"search"`
+	Status   string `json:"status"`
+	Username string `json:"username"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
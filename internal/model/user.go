@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 type UserRole string
 
 const (
@@ -7,3 +9,21 @@ const (
 	RoleAdmin    UserRole = "ADMIN"
 	RoleMember   UserRole = "MEMBER"
 )
+
+// Gin context keys set by the auth middleware.
+const (
+	RoleContextKey = "role"
+	UserContextKey = "user"
+)
+
+// ParseUserRole maps a stored role to a UserRole, falling back to RoleMember.
+func ParseUserRole(role string) UserRole {
+	switch UserRole(strings.ToUpper(strings.TrimSpace(role))) {
+	case RolePlatinum:
+		return RolePlatinum
+	case RoleAdmin:
+		return RoleAdmin
+	default:
+		return RoleMember
+	}
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+const (
+	LedgerEntryDebit  = "DEBIT"
+	LedgerEntryCredit = "CREDIT"
+)
+
+const (
+	LedgerReferenceDeposit     = "DEPOSIT"
+	LedgerReferenceTransaction = "TRANSACTION"
+	LedgerReferenceRefund      = "REFUND"
+)
+
+type LedgerEntry struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"userId"`
+	EntryType     string    `json:"entryType"`
+	Amount        int64     `json:"amount"`
+	BalanceBefore int64     `json:"balanceBefore"`
+	BalanceAfter  int64     `json:"balanceAfter"`
+	ReferenceType string    `json:"referenceType"`
+	ReferenceID   string    `json:"referenceId"`
+	Description   string    `json:"description"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// WalletMutation describes a single balance change and the business event behind it.
+type WalletMutation struct {
+	Username      string
+	EntryType     string
+	Amount        int64
+	ReferenceType string
+	ReferenceID   string
+	Description   string
+}
+
+type WalletBalance struct {
+	UserID   int    `json:"userId"`
+	Username string `json:"username"`
+	Balance  int64  `json:"balance"`
+}
+
+// WalletAudit compares the stored balance with what the ledger history proves.
+type WalletAudit struct {
+	UserID        int   `json:"userId"`
+	Balance       int64 `json:"balance"`
+	TotalCredit   int64 `json:"totalCredit"`
+	TotalDebit    int64 `json:"totalDebit"`
+	LedgerBalance int64 `json:"ledgerBalance"`
+	EntryCount    int   `json:"entryCount"`
+	BrokenEntries int   `json:"brokenEntries"`
+	Consistent    bool  `json:"consistent"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+const (
+	WebhookStatusPending   = "PENDING"
+	WebhookStatusDelivered = "DELIVERED"
+	WebhookStatusFailed    = "FAILED"
+)
+
+// WebhookDelivery is the order result webhook owed to a reseller. Payload is
+// stored as sent so every retry carries the same body.
+type WebhookDelivery struct {
+	ID               int              `json:"id"`
+	TransactionRefID string           `json:"transactionRefId"`
+	Username         string           `json:"username"`
+	URL              string           `json:"url"`
+	Payload          string           `json:"payload"`
+	Status           string           `json:"status"`
+	Attempts         int              `json:"attempts"`
+	LastStatusCode   *int             `json:"lastStatusCode,omitempty"`
+	LastError        *string          `json:"lastError,omitempty"`
+	NextAttemptAt    time.Time        `json:"nextAttemptAt"`
+	DeliveredAt      *time.Time       `json:"deliveredAt,omitempty"`
+	CreatedAt        time.Time        `json:"createdAt"`
+	UpdatedAt        time.Time        `json:"updatedAt"`
+	History          []WebhookAttempt `json:"history,omitempty"`
+}
+
+// WebhookAttempt is one POST made for a delivery.
+type WebhookAttempt struct {
+	ID         int       `json:"id"`
+	DeliveryID int       `json:"deliveryId"`
+	StatusCode *int      `json:"statusCode,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	DurationMs int       `json:"durationMs"`
+	Manual     bool      `json:"manual"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type FilterWebhookDelivery struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	Search   string `json:"search"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
@@ -1,24 +1,309 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-)
+	"log"
+	"strings"
+	"time"
 
+	"github.com/wafi04/otomaxv2/internal/model"
+)
 
 type AuthRepository struct {
 	repo *sql.DB
 }
 
-
-func NewAuthRepository(repo *sql.DB) *AuthRepository{
+func NewAuthRepository(repo *sql.DB) *AuthRepository {
 	return &AuthRepository{
 		repo: repo,
 	}
 }
 
+const userColumns = `
+	id, first_name, last_name, username, email, phone, avatar_url,
+	phone_verified_at, status, role, balance, created_at, updated_at`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*model.UserData, error) {
+	var user model.UserData
+	err := row.Scan(
+		&user.ID, &user.FristName, &user.LastName, &user.Username, &user.Email, &user.Phone,
+		&user.AvatarUrl, &user.PhoneVerifiedAt, &user.Status, &user.Role, &user.Balance,
+		&user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateGoogleUser creates a user for a Google profile whose email is new.
+// Google has verified the email, so it is stored as verified. An email or
+// Google ID that is already taken is reported as ErrDuplicate.
+func (repo *AuthRepository) CreateGoogleUser(ctx context.Context, profile model.GoogleCallback, username string) (*model.UserData, error) {
+	query := `
+		INSERT INTO users (
+			first_name, last_name, username, email, avatar_url, google_id,
+			email_verified_at, status, role, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, LOWER($4), $5, $6, NOW(), $7, $8, NOW(), NOW()
+		)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(repo.repo.QueryRowContext(ctx, query,
+		profile.GiveName, profile.FamilyName, username, profile.Email, profile.Picture, profile.ID,
+		model.UserStatusActive, model.RoleMember,
+	))
+	if isUniqueViolation(err) {
+		return nil, ErrDuplicate
+	}
+	if err != nil {
+		log.Printf("CreateGoogleUser error: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (repo *AuthRepository) GetByGoogleID(ctx context.Context, googleID string) (*model.UserData, error) {
+	user, err := scanUser(repo.repo.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE google_id = $1`, googleID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByGoogleID error: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetAccountLink returns the account owning email with what decides whether a
+// Google login may be attached to it, or nil when the email is unused.
+func (repo *AuthRepository) GetAccountLink(ctx context.Context, email string) (*model.AccountLink, error) {
+	query := `
+		SELECT ` + userColumns + `, COALESCE(google_id, ''), password_hash IS NOT NULL, email_verified_at IS NOT NULL
+		FROM users
+		WHERE LOWER(email) = LOWER($1)`
 
-// func (repo *AuthRepository) Create(){
-// 	state := "randomstate123" 
-// 	url := config.GoogleOauthConfig.AuthCodeURL(state)
-// 	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
-// }
\ No newline at end of file
+	var user model.UserData
+	link := model.AccountLink{User: &user}
+	err := repo.repo.QueryRowContext(ctx, query, email).Scan(
+		&user.ID, &user.FristName, &user.LastName, &user.Username, &user.Email, &user.Phone,
+		&user.AvatarUrl, &user.PhoneVerifiedAt, &user.Status, &user.Role, &user.Balance,
+		&user.CreatedAt, &user.UpdatedAt, &link.GoogleID, &link.HasPassword, &link.EmailVerified,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetAccountLink error: %v", err)
+		return nil, err
+	}
+	return &link, nil
+}
+
+// UpdateGoogleProfile refreshes the name and avatar of a linked user.
+func (repo *AuthRepository) UpdateGoogleProfile(ctx context.Context, userID int, profile model.GoogleCallback) (*model.UserData, error) {
+	query := `
+		UPDATE users
+		SET first_name = $1, last_name = $2, avatar_url = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING ` + userColumns
+
+	user, err := scanUser(repo.repo.QueryRowContext(ctx, query,
+		profile.GiveName, profile.FamilyName, profile.Picture, userID,
+	))
+	if err != nil {
+		log.Printf("UpdateGoogleProfile error: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// LinkGoogle attaches a Google ID to a user that has none yet. When the Google
+// email is the user's own, the email becomes verified. It returns nil when the
+// user already has another Google account, and ErrDuplicate when the Google
+// account belongs to someone else.
+func (repo *AuthRepository) LinkGoogle(ctx context.Context, userID int, profile model.GoogleCallback) (*model.UserData, error) {
+	query := `
+		UPDATE users
+		SET google_id = $1,
+			email_verified_at = CASE WHEN LOWER(email) = LOWER($2) THEN COALESCE(email_verified_at, NOW())
+				ELSE email_verified_at END,
+			updated_at = NOW()
+		WHERE id = $3 AND (google_id IS NULL OR google_id = $1)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(repo.repo.QueryRowContext(ctx, query, profile.ID, profile.Email, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrDuplicate
+	}
+	if err != nil {
+		log.Printf("LinkGoogle error: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (repo *AuthRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	var exists bool
+	err := repo.repo.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`, username).Scan(&exists)
+	return exists, err
+}
+
+func (repo *AuthRepository) GetUserByID(ctx context.Context, id int) (*model.UserData, error) {
+	user, err := scanUser(repo.repo.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetUserByID error: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (repo *AuthRepository) CreateRefreshToken(ctx context.Context, tokenID string, userID int, expiresAt time.Time) error {
+	query := `
+		INSERT INTO refresh_tokens (token_id, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, NOW())`
+
+	_, err := repo.repo.ExecContext(ctx, query, tokenID, userID, expiresAt)
+	if err != nil {
+		log.Printf("CreateRefreshToken error: %v", err)
+	}
+	return err
+}
+
+// RevokeRefreshToken revokes a live refresh token and returns its owner. It
+// returns false when the token is unknown, expired or already revoked, so a
+// token can only be spent once.
+func (repo *AuthRepository) RevokeRefreshToken(ctx context.Context, tokenID string) (int, bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE token_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`
+
+	var userID int
+	err := repo.repo.QueryRowContext(ctx, query, tokenID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		log.Printf("RevokeRefreshToken error: %v", err)
+		return 0, false, err
+	}
+	return userID, true, nil
+}
+
+// CreateUser inserts a password-based account. A duplicate username, email or
+// phone is reported as ErrDuplicate.
+func (repo *AuthRepository) CreateUser(ctx context.Context, req model.RegisterRequest, passwordHash string) (*model.UserData, error) {
+	query := `
+		INSERT INTO users (
+			first_name, last_name, username, email, phone, password_hash,
+			status, role, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, LOWER($4), $5, $6, $7, $8, NOW(), NOW()
+		)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(repo.repo.QueryRowContext(ctx, query,
+		req.FirstName, req.LastName, req.Username, req.Email, req.Phone, passwordHash,
+		model.UserStatusActive, model.RoleMember,
+	))
+	if isUniqueViolation(err) {
+		return nil, ErrDuplicate
+	}
+	if err != nil {
+		log.Printf("CreateUser error: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetCredentials looks a user up by email when the identifier contains "@" and
+// by username otherwise, and returns the stored password hash, which is empty
+// for Google-only accounts.
+func (repo *AuthRepository) GetCredentials(ctx context.Context, identifier string) (*model.UserData, string, error) {
+	where := `username = $1`
+	if strings.Contains(identifier, "@") {
+		where = `LOWER(email) = LOWER($1)`
+	}
+	query := `
+		SELECT ` + userColumns + `, COALESCE(password_hash, '')
+		FROM users
+		WHERE ` + where
+
+	var user model.UserData
+	var passwordHash string
+	err := repo.repo.QueryRowContext(ctx, query, identifier).Scan(
+		&user.ID, &user.FristName, &user.LastName, &user.Username, &user.Email, &user.Phone,
+		&user.AvatarUrl, &user.PhoneVerifiedAt, &user.Status, &user.Role, &user.Balance,
+		&user.CreatedAt, &user.UpdatedAt, &passwordHash,
+	)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		log.Printf("GetCredentials error: %v", err)
+		return nil, "", err
+	}
+	return &user, passwordHash, nil
+}
+
+func (repo *AuthRepository) MarkPhoneVerified(ctx context.Context, userID int) error {
+	_, err := repo.repo.ExecContext(ctx, `UPDATE users SET phone_verified_at = NOW(), updated_at = NOW() WHERE id = $1`, userID)
+	if err != nil {
+		log.Printf("MarkPhoneVerified error: %v", err)
+	}
+	return err
+}
+
+// GetPINHash returns the user's transaction PIN hash, or "" when none is set.
+func (repo *AuthRepository) GetPINHash(ctx context.Context, userID int) (string, error) {
+	var pinHash string
+	err := repo.repo.QueryRowContext(ctx, `SELECT COALESCE(pin_hash, '') FROM users WHERE id = $1`, userID).Scan(&pinHash)
+	if err != nil {
+		log.Printf("GetPINHash error: %v", err)
+		return "", err
+	}
+	return pinHash, nil
+}
+
+func (repo *AuthRepository) SetPIN(ctx context.Context, userID int, pinHash string) error {
+	_, err := repo.repo.ExecContext(ctx, `UPDATE users SET pin_hash = $1, updated_at = NOW() WHERE id = $2`, pinHash, userID)
+	if err != nil {
+		log.Printf("SetPIN error: %v", err)
+	}
+	return err
+}
+
+// GetByPhone returns the user with a verified phone number and the PIN hash
+// they sign text commands with.
+func (repo *AuthRepository) GetByPhone(ctx context.Context, phone string) (*model.UserData, string, error) {
+	query := `
+		SELECT ` + userColumns + `, COALESCE(pin_hash, '')
+		FROM users
+		WHERE phone = $1 AND phone_verified_at IS NOT NULL
+		LIMIT 1`
+
+	var user model.UserData
+	var pinHash string
+	err := repo.repo.QueryRowContext(ctx, query, phone).Scan(
+		&user.ID, &user.FristName, &user.LastName, &user.Username, &user.Email, &user.Phone,
+		&user.AvatarUrl, &user.PhoneVerifiedAt, &user.Status, &user.Role, &user.Balance,
+		&user.CreatedAt, &user.UpdatedAt, &pinHash,
+	)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		log.Printf("GetByPhone error: %v", err)
+		return nil, "", err
+	}
+	return &user, pinHash, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type BillRepository struct {
+	DB *sql.DB
+}
+
+func NewBillRepository(db *sql.DB) *BillRepository {
+	return &BillRepository{DB: db}
+}
+
+func (repo *BillRepository) Create(ctx context.Context, bill *model.Bill) error {
+	query := `
+		INSERT INTO postpaid_bills (
+			ref_id, username, product_id, provider_product_id, provider_code, provider_slug,
+			customer_no, customer_name, period, amount, admin_fee, cost_price, total,
+			status, expires_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW()
+		) RETURNING id, created_at, updated_at`
+
+	err := repo.DB.QueryRowContext(ctx, query,
+		bill.RefID, bill.Username, bill.ProductID, bill.ProviderProductID, bill.ProviderCode, bill.ProviderSlug,
+		bill.CustomerNo, bill.CustomerName, bill.Period, bill.Amount, bill.AdminFee, bill.CostPrice, bill.Total,
+		bill.Status, bill.ExpiresAt,
+	).Scan(&bill.ID, &bill.CreatedAt, &bill.UpdatedAt)
+	if err != nil {
+		log.Printf("Create Bill error: %v", err)
+	}
+	return err
+}
+
+func (repo *BillRepository) GetByRefID(ctx context.Context, refID string) (*model.Bill, error) {
+	query := `
+		SELECT b.id, b.ref_id, b.username, b.product_id, p.name, b.provider_product_id,
+			b.provider_code, b.provider_slug, b.customer_no, b.customer_name, b.period,
+			b.amount, b.admin_fee, b.cost_price, b.total, b.status, b.transaction_ref_id,
+			b.expires_at, b.created_at, b.updated_at
+		FROM postpaid_bills b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.ref_id = $1`
+
+	var bill model.Bill
+	err := repo.DB.QueryRowContext(ctx, query, refID).Scan(
+		&bill.ID, &bill.RefID, &bill.Username, &bill.ProductID, &bill.ProductName, &bill.ProviderProductID,
+		&bill.ProviderCode, &bill.ProviderSlug, &bill.CustomerNo, &bill.CustomerName, &bill.Period,
+		&bill.Amount, &bill.AdminFee, &bill.CostPrice, &bill.Total, &bill.Status, &bill.TransactionRefID,
+		&bill.ExpiresAt, &bill.CreatedAt, &bill.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByRefID Bill error: %v", err)
+		return nil, err
+	}
+	return &bill, nil
+}
+
+// Claim ties an open, unexpired bill to the transaction paying it. It returns
+// false when the bill was already paid or has expired.
+func (repo *BillRepository) Claim(ctx context.Context, exec DBTX, refID, transactionRefID string) (bool, error) {
+	query := `
+		UPDATE postpaid_bills
+		SET status = $1, transaction_ref_id = $2, updated_at = NOW()
+		WHERE ref_id = $3 AND status = $4 AND expires_at > NOW()`
+
+	res, err := exec.ExecContext(ctx, query, model.BillStatusOrdered, transactionRefID, refID, model.BillStatusOpen)
+	if err != nil {
+		log.Printf("Claim Bill error: %v", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -23,11 +23,11 @@ func (repo *CategoryRepository) Create(ctx context.Context, category model.Creat
 		INSERT INTO categories (
 			name, sub_name, brand, code, is_check_nickname, status,
 			thumbnail, type, instruction, information, banner, placeholder_1, placeholder_2,
-			created_at, updated_at
+			duplicate_window_minutes, created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
 			$8, $9, $10, $11, $12, $13,
-			NOW(), NOW()
+			$14, NOW(), NOW()
 		)
 	`
 
@@ -35,7 +35,7 @@ func (repo *CategoryRepository) Create(ctx context.Context, category model.Creat
 		category.Name, category.SubName, category.Brand, category.Code,
 		category.IsCheckNickname, category.Status, category.Thumbnail, category.Type,
 		category.Instruction, category.Information, category.Banner,
-		category.Placeholder1, category.Placeholder2,
+		category.Placeholder1, category.Placeholder2, category.DuplicateWindowMinutes,
 	)
 	if err != nil {
 		log.Printf("Create Category error: %v", err)
@@ -49,15 +49,15 @@ func (repo *CategoryRepository) Update(ctx context.Context, id int, category mod
 		SET name = $1, sub_name = $2, brand = $3, code = $4, is_check_nickname = $5, status = $6,
 			thumbnail = $7, type = $8, instruction = $9, information = $10,
 			banner = $11, placeholder_1 = $12, placeholder_2 = $13,
-			updated_at = NOW()
-		WHERE id = $14
+			duplicate_window_minutes = $14, updated_at = NOW()
+		WHERE id = $15
 	`
 
 	_, err := repo.DB.ExecContext(ctx, query,
 		category.Name, category.SubName, category.Brand, category.Code,
 		category.IsCheckNickname, category.Status, category.Thumbnail, category.Type,
 		category.Instruction, category.Information, category.Banner,
-		category.Placeholder1, category.Placeholder2,
+		category.Placeholder1, category.Placeholder2, category.DuplicateWindowMinutes,
 		id,
 	)
 	if err != nil {
@@ -96,7 +96,7 @@ func (repo *CategoryRepository) GetAll(ctx context.Context, skip, limit int, sea
 	query := `
 		SELECT id, name, sub_name, brand, code, is_check_nickname, status,
 			thumbnail, type, instruction, information,
-			banner, placeholder_1, placeholder_2, created_at, updated_at
+			banner, placeholder_1, placeholder_2, duplicate_window_minutes, created_at, updated_at
 		FROM categories
 		WHERE ($1 = '' OR name ILIKE '%' || $1 || '%')
 		  AND ($2 = '' OR type = $2)
@@ -119,7 +119,7 @@ func (repo *CategoryRepository) GetAll(ctx context.Context, skip, limit int, sea
 			&cat.ID, &cat.Name, &cat.SubName, &cat.Brand, &cat.Code,
 			&cat.IsCheckNickname, &cat.Status, &cat.Thumbnail, &cat.Type,
 			&cat.Instruction, &cat.Information, &cat.Banner, &cat.Placeholder1,
-			&cat.Placeholder2, &cat.CreatedAt, &cat.UpdatedAt,
+			&cat.Placeholder2, &cat.DuplicateWindowMinutes, &cat.CreatedAt, &cat.UpdatedAt,
 		)
 		if err != nil {
 			log.Printf("Scan Category error: %v", err)
@@ -139,7 +139,7 @@ func (repo *CategoryRepository) GetByID(ctx context.Context, id int) (*model.Cat
 	query := `
 		SELECT id, name, sub_name, brand, code, is_check_nickname, status,
 			thumbnail, type, instruction, information,
-			banner, placeholder_1, placeholder_2, created_at, updated_at
+			banner, placeholder_1, placeholder_2, duplicate_window_minutes, created_at, updated_at
 		FROM categories
 		WHERE id = $1
 	`
@@ -149,7 +149,7 @@ func (repo *CategoryRepository) GetByID(ctx context.Context, id int) (*model.Cat
 		&cat.ID, &cat.Name, &cat.SubName, &cat.Brand, &cat.Code,
 		&cat.IsCheckNickname, &cat.Status, &cat.Thumbnail, &cat.Type,
 		&cat.Instruction, &cat.Information, &cat.Banner, &cat.Placeholder1,
-		&cat.Placeholder2, &cat.CreatedAt, &cat.UpdatedAt,
+		&cat.Placeholder2, &cat.DuplicateWindowMinutes, &cat.CreatedAt, &cat.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -161,8 +161,35 @@ func (repo *CategoryRepository) GetByID(ctx context.Context, id int) (*model.Cat
 	return &cat, nil
 }
 
+func (repo *CategoryRepository) GetByCode(ctx context.Context, code string) (*model.Category, error) {
+	query := `
+		SELECT id, name, sub_name, brand, code, is_check_nickname, status,
+			thumbnail, type, instruction, information,
+			banner, placeholder_1, placeholder_2, duplicate_window_minutes, created_at, updated_at
+		FROM categories
+		WHERE code = $1
+	`
+
+	var cat model.Category
+	err := repo.DB.QueryRowContext(ctx, query, code).Scan(
+		&cat.ID, &cat.Name, &cat.SubName, &cat.Brand, &cat.Code,
+		&cat.IsCheckNickname, &cat.Status, &cat.Thumbnail, &cat.Type,
+		&cat.Instruction, &cat.Information, &cat.Banner, &cat.Placeholder1,
+		&cat.Placeholder2, &cat.DuplicateWindowMinutes, &cat.CreatedAt, &cat.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByCode Category error: %v", err)
+		return nil, err
+	}
+	return &cat, nil
+}
+
 type CategoryFilter struct {
 	SubCategoryID *int
+	Role          model.UserRole
 }
 
 func (repo *CategoryRepository) GetByCodeWithFilter(ctx context.Context, code string, filter *CategoryFilter) (*model.CategoryCodeResponse, error) {
@@ -214,23 +241,30 @@ func (repo *CategoryRepository) GetByCodeWithFilter(ctx context.Context, code st
 		return nil, err
 	}
 
+	role := model.RoleMember
+	if filter != nil {
+		role = filter.Role
+	}
+
 	var productQuery string
 	var productArgs []interface{}
 
 	if filter != nil && filter.SubCategoryID != nil && *filter.SubCategoryID > 0 {
 		productQuery = `
-			SELECT id, name, price, denomination_type, sub_category_id
-			FROM products 
-			WHERE category_id = $1 AND sub_category_id = $2 AND status = 'active'
-			ORDER BY name`
+			SELECT p.id, p.name, p.` + priceColumn(role) + `, p.denomination_type, p.sub_category_id,
+				` + productInCutOff + `
+			FROM products p
+			WHERE p.category_id = $1 AND p.sub_category_id = $2 AND p.status = 'active'
+			ORDER BY p.name`
 		productArgs = []interface{}{cat.ID, *filter.SubCategoryID}
 	} else {
 		// Jika tidak ada filter subcategory, ambil semua products dari category
 		productQuery = `
-			SELECT id, name, price, denomination_type, sub_category_id
-			FROM products 
-			WHERE category_id = $1 AND status = 'active'
-			ORDER BY name`
+			SELECT p.id, p.name, p.` + priceColumn(role) + `, p.denomination_type, p.sub_category_id,
+				` + productInCutOff + `
+			FROM products p
+			WHERE p.category_id = $1 AND p.status = 'active'
+			ORDER BY p.name`
 		productArgs = []interface{}{cat.ID}
 	}
 
@@ -244,7 +278,7 @@ func (repo *CategoryRepository) GetByCodeWithFilter(ctx context.Context, code st
 		var prod model.Product
 		err := productRows.Scan(
 			&prod.ID, &prod.Name, &prod.Price,
-			&prod.DenominationType, &prod.SubCategoryID,
+			&prod.DenominationType, &prod.SubCategoryID, &prod.InCutOff,
 		)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,30 @@
+package repository
+
+import "github.com/wafi04/otomaxv2/internal/model"
+
+const jakartaTime = `(NOW() AT TIME ZONE '` + model.CutOffTimeZone + `')::time`
+
+// inCutOff is true when the provider_products row aliased pp is inside its
+// cut-off window right now. A window whose start equals its end means the SKU
+// has no cut-off, and a start after the end wraps past midnight.
+const inCutOff = `(pp.start_cut_off IS NOT NULL AND pp.end_cut_off IS NOT NULL
+	AND pp.start_cut_off <> pp.end_cut_off
+	AND CASE WHEN pp.start_cut_off < pp.end_cut_off
+		THEN ` + jakartaTime + ` >= pp.start_cut_off AND ` + jakartaTime + ` < pp.end_cut_off
+		ELSE ` + jakartaTime + ` >= pp.start_cut_off OR ` + jakartaTime + ` < pp.end_cut_off
+	END)`
+
+// productInCutOff is true for a product (aliased p) that can only be fulfilled
+// by SKUs that are currently in cut-off.
+const productInCutOff = `(
+	EXISTS (
+		SELECT 1 FROM provider_products pp
+		WHERE pp.product_id = p.id AND pp.is_available = true AND pp.is_maintenance = false
+		  AND ` + inCutOff + `
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM provider_products pp
+		WHERE pp.product_id = p.id AND pp.is_available = true AND pp.is_maintenance = false
+		  AND NOT ` + inCutOff + `
+	)
+)`
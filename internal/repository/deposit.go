@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"time"
 
 	"github.com/wafi04/otomaxv2/internal/model"
 )
@@ -18,9 +19,35 @@ func NewDepositRepository(db *sql.DB) *DepositRepository {
 	}
 }
 
+const depositColumns = `
+	id,
+	deposit_id,
+	username,
+	method,
+	amount,
+	payment_referee,
+	destination_number,
+	status,
+	status_checks,
+	created_at,
+	updated_at`
+
+func scanDeposit(row interface{ Scan(...interface{}) error }) (*model.DepositData, error) {
+	var dep model.DepositData
+	err := row.Scan(
+		&dep.ID, &dep.DepositID, &dep.Username, &dep.Method, &dep.Amount, &dep.PaymentReferee,
+		&dep.DestinationNumber, &dep.Status, &dep.StatusChecks, &dep.CreatedAt, &dep.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
 func (repo *DepositRepository) Create(c context.Context, req model.CreateDeposit) (bool, error) {
 	query := `
 		INSERT INTO deposits (
+			deposit_id,
 			username,
 			method,
 			amount,
@@ -30,13 +57,17 @@ func (repo *DepositRepository) Create(c context.Context, req model.CreateDeposit
 			created_at,
 			updated_at
 		) VALUES (
-			$1,$2,$3,$4,$5,'PENDING',NOW(),NOW()
+			$1,$2,$3,$4,$5,$6,$7,NOW(),NOW()
 		)
 	`
-	_, err := repo.db.ExecContext(c, query, &req.Username, &req.Method, &req.PaymentReferee, &req.DestinationNumber)
+	_, err := repo.db.ExecContext(c, query,
+		req.DepositID, req.Username, req.Method, req.Amount, req.PaymentReferee,
+		req.DestinationNumber, model.DepositStatusPending,
+	)
 
 	if err != nil {
-		return false, nil
+		log.Printf("Create Deposit error: %v", err)
+		return false, err
 	}
 
 	return true, nil
@@ -44,8 +75,8 @@ func (repo *DepositRepository) Create(c context.Context, req model.CreateDeposit
 
 func (repo *DepositRepository) GetAll(c context.Context, req model.FilterDeposit) ([]model.DepositData, int, error) {
 	countQuery := `
-		SELECT COUNT(*) 
-		FROM categories
+		SELECT COUNT(*)
+		FROM deposits
 		WHERE ($1 = '' OR username ILIKE '%' || $1 || '%')
 		  AND ($2 = '' OR status  = $2)
 	`
@@ -53,21 +84,12 @@ func (repo *DepositRepository) GetAll(c context.Context, req model.FilterDeposit
 	var totalCount int
 	err := repo.db.QueryRowContext(c, countQuery, req.Search, req.Status).Scan(&totalCount)
 	if err != nil {
-		log.Printf("GetAll Categories count error: %v", err)
+		log.Printf("GetAll Deposits count error: %v", err)
 		return nil, 0, err
 	}
 
 	query := `
-		SELECT 
-			id, 
-			username,
-			method,
-			amount,
-			payement_referee,
-			destination_number,
-			status,
-			created_at, 
-			updated_at
+		SELECT ` + depositColumns + `
 		FROM deposits
 		WHERE ($1 = '' OR username ILIKE '%' || $1 || '%')
 		  AND ($2 = '' OR status = $2)
@@ -83,16 +105,12 @@ func (repo *DepositRepository) GetAll(c context.Context, req model.FilterDeposit
 
 	var deposits []model.DepositData
 	for rows.Next() {
-		var dep model.DepositData
-		err := rows.Scan(
-			&dep.ID, &dep.Username, &dep.Method, &dep.Amount, &dep.PaymentReferee,
-			&dep.DestinationNumber, &dep.Status, &dep.CreatedAt, &dep.UpdatedAt,
-		)
+		dep, err := scanDeposit(rows)
 		if err != nil {
 			log.Printf("Scan Deposit error: %v", err)
 			continue
 		}
-		deposits = append(deposits, dep)
+		deposits = append(deposits, *dep)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -105,31 +123,105 @@ func (repo *DepositRepository) GetAll(c context.Context, req model.FilterDeposit
 
 func (repo *DepositRepository) GetByID(ctx context.Context, id int) (*model.DepositData, error) {
 	query := `
-		SELECT 
-			id, 
-			username,
-			method,
-			amount,
-			payement_referee,
-			destination_number,
-			status,
-			created_at, 
-			updated_at
+		SELECT ` + depositColumns + `
 		FROM deposits
 		WHERE id = $1
 	`
 
-	var dep model.DepositData
-	err := repo.db.QueryRowContext(ctx, query, id).Scan(
-		&dep.ID, &dep.Username, &dep.Method, &dep.Amount, &dep.PaymentReferee,
-		&dep.DestinationNumber, &dep.Status, &dep.CreatedAt, &dep.UpdatedAt,
-	)
+	dep, err := scanDeposit(repo.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
-		log.Printf("GetByID Category error: %v", err)
+		log.Printf("GetByID Deposit error: %v", err)
 		return nil, err
 	}
-	return &dep, nil
+	return dep, nil
+}
+
+func (repo *DepositRepository) GetByDepositID(ctx context.Context, depositID string) (*model.DepositData, error) {
+	query := `
+		SELECT ` + depositColumns + `
+		FROM deposits
+		WHERE deposit_id = $1
+	`
+
+	dep, err := scanDeposit(repo.db.QueryRowContext(ctx, query, depositID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByDepositID Deposit error: %v", err)
+		return nil, err
+	}
+	return dep, nil
+}
+
+// GetPendingBefore returns deposits still PENDING that were created before the
+// given time and are due for a status check.
+func (repo *DepositRepository) GetPendingBefore(ctx context.Context, before time.Time, limit int) ([]model.DepositData, error) {
+	query := `
+		SELECT ` + depositColumns + `
+		FROM deposits
+		WHERE status = $1 AND created_at < $2
+		  AND (next_check_at IS NULL OR next_check_at <= NOW())
+		ORDER BY next_check_at NULLS FIRST, created_at
+		LIMIT $3
+	`
+
+	rows, err := repo.db.QueryContext(ctx, query, model.DepositStatusPending, before, limit)
+	if err != nil {
+		log.Printf("GetPendingBefore Deposits error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []model.DepositData
+	for rows.Next() {
+		dep, err := scanDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, *dep)
+	}
+
+	return deposits, rows.Err()
+}
+
+// ScheduleCheck counts a status check that left the deposit PENDING and sets
+// when the next one is due.
+func (repo *DepositRepository) ScheduleCheck(ctx context.Context, depositID string, next time.Time) error {
+	query := `
+		UPDATE deposits
+		SET status_checks = status_checks + 1, next_check_at = $1
+		WHERE deposit_id = $2
+	`
+
+	_, err := repo.db.ExecContext(ctx, query, next, depositID)
+	if err != nil {
+		log.Printf("ScheduleCheck Deposit error: %v", err)
+	}
+	return err
+}
+
+// UpdateStatus settles a PENDING deposit. It reports false when the deposit was
+// already settled, which makes repeated callbacks harmless.
+func (repo *DepositRepository) UpdateStatus(ctx context.Context, exec DBTX, depositID, status string, reference *string) (bool, error) {
+	query := `
+		UPDATE deposits
+		SET status = $1, payment_referee = COALESCE($2, payment_referee), updated_at = NOW()
+		WHERE deposit_id = $3 AND status = $4
+	`
+
+	res, err := exec.ExecContext(ctx, query, status, reference, depositID, model.DepositStatusPending)
+	if err != nil {
+		log.Printf("UpdateStatus Deposit error: %v", err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
 }
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+// GatewayRepository resolves the keywords used by the text command gateway.
+type GatewayRepository struct {
+	DB *sql.DB
+}
+
+func NewGatewayRepository(db *sql.DB) *GatewayRepository {
+	return &GatewayRepository{DB: db}
+}
+
+// productKeyword is a product's keyword, falling back to its source SKU code.
+const productKeyword = `UPPER(COALESCE(p.keyword, p.source_code))`
+
+// productOrderable is true while one of the product's SKUs can take an order.
+const productOrderable = `p.status = 'active' AND EXISTS (
+		SELECT 1 FROM provider_products pp
+		WHERE pp.product_id = p.id
+		  AND pp.is_available = true
+		  AND pp.is_maintenance = false
+		  AND NOT pp.is_postpaid
+		  AND NOT ` + inCutOff + `
+	)`
+
+// GetByKeyword resolves an upper-case keyword. An explicit keyword wins over a
+// source code that happens to match it.
+func (repo *GatewayRepository) GetByKeyword(ctx context.Context, keyword string, role model.UserRole) (*model.KeywordProduct, error) {
+	query := `
+		SELECT p.id, ` + productKeyword + `, p.name, p.` + priceColumn(role) + `, ` + productOrderable + `
+		FROM products p
+		WHERE p.keyword = $1 OR (p.keyword IS NULL AND UPPER(p.source_code) = $1)
+		ORDER BY p.keyword IS NULL, p.id
+		LIMIT 1`
+
+	var product model.KeywordProduct
+	err := repo.DB.QueryRowContext(ctx, query, keyword).Scan(
+		&product.ProductID, &product.Keyword, &product.Name, &product.Price, &product.Available,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByKeyword error: %v", err)
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetByKeywordPrefix lists orderable prepaid products whose keyword starts with
+// prefix, cheapest first.
+func (repo *GatewayRepository) GetByKeywordPrefix(ctx context.Context, prefix string, role model.UserRole, limit int) ([]model.KeywordProduct, error) {
+	query := `
+		SELECT p.id, ` + productKeyword + `, p.name, p.` + priceColumn(role) + `, true
+		FROM products p
+		WHERE ` + productKeyword + ` LIKE $1 || '%'
+		  AND ` + productOrderable + `
+		ORDER BY p.` + priceColumn(role) + `, 2
+		LIMIT $2`
+
+	rows, err := repo.DB.QueryContext(ctx, query, prefix, limit)
+	if err != nil {
+		log.Printf("GetByKeywordPrefix error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []model.KeywordProduct
+	for rows.Next() {
+		var product model.KeywordProduct
+		if err := rows.Scan(&product.ProductID, &product.Keyword, &product.Name, &product.Price, &product.Available); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
+// SetKeyword sets or, with an empty keyword, clears a product's keyword.
+func (repo *GatewayRepository) SetKeyword(ctx context.Context, productID int, keyword string) (bool, error) {
+	res, err := repo.DB.ExecContext(ctx, `
+		UPDATE products SET keyword = NULLIF($1, ''), updated_at = NOW() WHERE id = $2`, keyword, productID)
+	if isUniqueViolation(err) {
+		return false, ErrDuplicate
+	}
+	if err != nil {
+		log.Printf("SetKeyword error: %v", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type IdempotencyRepository struct {
+	DB *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{DB: db}
+}
+
+const idempotencyKeyColumns = `
+	id, scope, owner, key, request_hash, status, response_status, response_body, expires_at, created_at`
+
+func scanIdempotencyKey(row interface{ Scan(...interface{}) error }) (*model.IdempotencyKey, error) {
+	var k model.IdempotencyKey
+	err := row.Scan(
+		&k.ID, &k.Scope, &k.Owner, &k.Key, &k.RequestHash, &k.Status,
+		&k.ResponseStatus, &k.ResponseBody, &k.ExpiresAt, &k.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Begin claims the key for a new request. It reports false with the stored
+// record when the key is already in use; an expired key is claimed afresh.
+func (repo *IdempotencyRepository) Begin(ctx context.Context, k *model.IdempotencyKey) (*model.IdempotencyKey, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, owner, key, request_hash, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (scope, owner, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status = EXCLUDED.status,
+			response_status = NULL, response_body = NULL, expires_at = EXCLUDED.expires_at,
+			created_at = NOW(), updated_at = NOW()
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING ` + idempotencyKeyColumns
+
+	claimed, err := scanIdempotencyKey(repo.DB.QueryRowContext(ctx, query,
+		k.Scope, k.Owner, k.Key, k.RequestHash, model.IdempotencyStatusInProgress, k.ExpiresAt,
+	))
+	if err == nil {
+		return claimed, true, nil
+	}
+	if err != sql.ErrNoRows {
+		log.Printf("Begin IdempotencyKey error: %v", err)
+		return nil, false, err
+	}
+
+	existing, err := scanIdempotencyKey(repo.DB.QueryRowContext(ctx, `
+		SELECT `+idempotencyKeyColumns+`
+		FROM idempotency_keys
+		WHERE scope = $1 AND owner = $2 AND key = $3`,
+		k.Scope, k.Owner, k.Key,
+	))
+	if err != nil {
+		log.Printf("Begin IdempotencyKey lookup error: %v", err)
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// Complete stores the response that repeats of the key will receive.
+func (repo *IdempotencyRepository) Complete(ctx context.Context, id, status int, body string) error {
+	_, err := repo.DB.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status = $1, response_status = $2, response_body = $3, updated_at = NOW()
+		WHERE id = $4`,
+		model.IdempotencyStatusCompleted, status, body, id,
+	)
+	if err != nil {
+		log.Printf("Complete IdempotencyKey error: %v", err)
+	}
+	return err
+}
+
+// Release frees a key whose request ended without a response, so the client
+// can retry with it.
+func (repo *IdempotencyRepository) Release(ctx context.Context, id int) error {
+	_, err := repo.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE id = $1 AND status = $2`,
+		id, model.IdempotencyStatusInProgress)
+	if err != nil {
+		log.Printf("Release IdempotencyKey error: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type MarkupRuleRepository struct {
+	DB *sql.DB
+}
+
+func NewMarkupRuleRepository(db *sql.DB) *MarkupRuleRepository {
+	return &MarkupRuleRepository{DB: db}
+}
+
+const markupRuleColumns = `
+	id, name, role, provider, category, brand, type, min_cost, max_cost,
+	markup_type, markup_value, min_profit, rounding, priority, is_active,
+	created_at, updated_at`
+
+func scanMarkupRule(row interface{ Scan(...interface{}) error }) (*model.MarkupRule, error) {
+	var rule model.MarkupRule
+	err := row.Scan(
+		&rule.ID, &rule.Name, &rule.Role, &rule.Provider, &rule.Category, &rule.Brand, &rule.Type,
+		&rule.MinCost, &rule.MaxCost, &rule.MarkupType, &rule.MarkupValue, &rule.MinProfit,
+		&rule.Rounding, &rule.Priority, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (repo *MarkupRuleRepository) Create(ctx context.Context, req model.CreateMarkupRule) (*model.MarkupRule, error) {
+	query := `
+		INSERT INTO markup_rules (
+			name, role, provider, category, brand, type, min_cost, max_cost,
+			markup_type, markup_value, min_profit, rounding, priority, is_active,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW(), NOW()
+		) RETURNING ` + markupRuleColumns
+
+	rule, err := scanMarkupRule(repo.DB.QueryRowContext(ctx, query,
+		req.Name, req.Role, req.Provider, req.Category, req.Brand, req.Type, req.MinCost, req.MaxCost,
+		req.MarkupType, req.MarkupValue, req.MinProfit, req.Rounding, req.Priority, isActive(req.IsActive),
+	))
+	if err != nil {
+		log.Printf("Create MarkupRule error: %v", err)
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (repo *MarkupRuleRepository) Update(ctx context.Context, id int, req model.CreateMarkupRule) (*model.MarkupRule, error) {
+	query := `
+		UPDATE markup_rules
+		SET name = $1, role = $2, provider = $3, category = $4, brand = $5, type = $6,
+			min_cost = $7, max_cost = $8, markup_type = $9, markup_value = $10,
+			min_profit = $11, rounding = $12, priority = $13, is_active = $14, updated_at = NOW()
+		WHERE id = $15
+		RETURNING ` + markupRuleColumns
+
+	rule, err := scanMarkupRule(repo.DB.QueryRowContext(ctx, query,
+		req.Name, req.Role, req.Provider, req.Category, req.Brand, req.Type, req.MinCost, req.MaxCost,
+		req.MarkupType, req.MarkupValue, req.MinProfit, req.Rounding, req.Priority, isActive(req.IsActive), id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Update MarkupRule error: %v", err)
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (repo *MarkupRuleRepository) Delete(ctx context.Context, id int) (bool, error) {
+	res, err := repo.DB.ExecContext(ctx, `DELETE FROM markup_rules WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Delete MarkupRule error: %v", err)
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (repo *MarkupRuleRepository) GetAll(ctx context.Context) ([]model.MarkupRule, error) {
+	return repo.list(ctx, `SELECT `+markupRuleColumns+` FROM markup_rules ORDER BY role, priority DESC, id`)
+}
+
+// GetActive returns the active rules in evaluation order.
+func (repo *MarkupRuleRepository) GetActive(ctx context.Context) ([]model.MarkupRule, error) {
+	return repo.list(ctx, `SELECT `+markupRuleColumns+` FROM markup_rules WHERE is_active ORDER BY priority DESC, id`)
+}
+
+func (repo *MarkupRuleRepository) list(ctx context.Context, query string) ([]model.MarkupRule, error) {
+	rows, err := repo.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("List MarkupRules error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []model.MarkupRule
+	for rows.Next() {
+		rule, err := scanMarkupRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+func isActive(active *bool) bool {
+	return active == nil || *active
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type PriceHistoryRepository struct {
+	DB *sql.DB
+}
+
+func NewPriceHistoryRepository(db *sql.DB) *PriceHistoryRepository {
+	return &PriceHistoryRepository{DB: db}
+}
+
+const priceHistoryColumns = `
+	h.id, h.provider_product_id, pp.provider_code, h.product_id, p.name, h.sync_run_id,
+	h.old_cost_price, h.new_cost_price, h.old_selling_price, h.new_selling_price,
+	h.change_percent, h.alert_type, h.created_at`
+
+func scanPriceHistory(row interface{ Scan(...interface{}) error }) (*model.PriceHistory, error) {
+	var h model.PriceHistory
+	err := row.Scan(
+		&h.ID, &h.ProviderProductID, &h.ProviderCode, &h.ProductID, &h.ProductName, &h.SyncRunID,
+		&h.OldCostPrice, &h.NewCostPrice, &h.OldSellingPrice, &h.NewSellingPrice,
+		&h.ChangePercent, &h.AlertType, &h.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// GetByProduct returns a product's price changes since a point in time,
+// oldest first so they can be charted directly.
+func (repo *PriceHistoryRepository) GetByProduct(ctx context.Context, productID int, since time.Time) ([]model.PriceHistory, error) {
+	query := `
+		SELECT ` + priceHistoryColumns + `
+		FROM price_history h
+		JOIN provider_products pp ON pp.id = h.provider_product_id
+		JOIN products p ON p.id = h.product_id
+		WHERE h.product_id = $1 AND h.created_at >= $2
+		ORDER BY h.created_at ASC, h.id ASC`
+
+	rows, err := repo.DB.QueryContext(ctx, query, productID, since)
+	if err != nil {
+		log.Printf("GetByProduct PriceHistory error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.PriceHistory
+	for rows.Next() {
+		h, err := scanPriceHistory(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, *h)
+	}
+	return history, rows.Err()
+}
+
+func (repo *PriceHistoryRepository) GetAlerts(ctx context.Context, filter model.FilterPriceAlert) ([]model.PriceHistory, int, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM price_history h
+		WHERE h.alert_type IS NOT NULL
+		  AND ($1 = '' OR h.alert_type = $1)
+		  AND ($2 = 0 OR h.sync_run_id = $2)`
+
+	var totalCount int
+	if err := repo.DB.QueryRowContext(ctx, countQuery, filter.Type, filter.SyncRunID).Scan(&totalCount); err != nil {
+		log.Printf("GetAlerts PriceHistory count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + priceHistoryColumns + `
+		FROM price_history h
+		JOIN provider_products pp ON pp.id = h.provider_product_id
+		JOIN products p ON p.id = h.product_id
+		WHERE h.alert_type IS NOT NULL
+		  AND ($1 = '' OR h.alert_type = $1)
+		  AND ($2 = 0 OR h.sync_run_id = $2)
+		ORDER BY h.created_at DESC, h.id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := repo.DB.QueryContext(ctx, query, filter.Type, filter.SyncRunID, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Printf("GetAlerts PriceHistory error: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var alerts []model.PriceHistory
+	for rows.Next() {
+		h, err := scanPriceHistory(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		alerts = append(alerts, *h)
+	}
+	return alerts, totalCount, rows.Err()
+}
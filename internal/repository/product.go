@@ -14,13 +14,25 @@ type ProductRepository struct {
 func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{DB: db}
 }
-func (pr *ProductRepository) GetProducts(ctx context.Context, limit, offset int) ([]*model.ProductData, error) {
+// priceColumn returns the products column holding the selling price for a role.
+func priceColumn(role model.UserRole) string {
+    switch role {
+    case model.RolePlatinum:
+        return "price_platinum"
+    case model.RoleAdmin:
+        return "price_admin"
+    default:
+        return "price_member"
+    }
+}
+
+func (pr *ProductRepository) GetProducts(ctx context.Context, role model.UserRole, limit, offset int) ([]*model.ProductData, error) {
     query := `
         SELECT 
             p.id,
             p.name AS product_name,
             p.description,
-            p.price,
+            p.` + priceColumn(role) + `,
             p.original_price,
             p.status,
             p.image,
@@ -32,7 +44,10 @@ func (pr *ProductRepository) GetProducts(ctx context.Context, limit, offset int)
             pp.profit_margin,
             pp.stock,
             pp.is_available,
-            pp.is_maintenance
+            pp.is_maintenance,
+            TO_CHAR(pp.start_cut_off, 'HH24:MI'),
+            TO_CHAR(pp.end_cut_off, 'HH24:MI'),
+            ` + inCutOff + `
         FROM products p
         JOIN provider_products pp ON pp.product_id = p.id
         ORDER BY p.id
@@ -76,6 +91,9 @@ func (pr *ProductRepository) GetProducts(ctx context.Context, limit, offset int)
             &provider.Stock,
             &provider.IsAvailable,
             &provider.IsMaintenance,
+            &provider.StartCutOff,
+            &provider.EndCutOff,
+            &provider.InCutOff,
         )
         if err != nil {
             return nil, err
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/lib/pq"
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type ResellerRepository struct {
+	DB *sql.DB
+}
+
+func NewResellerRepository(db *sql.DB) *ResellerRepository {
+	return &ResellerRepository{DB: db}
+}
+
+const resellerKeyColumns = `
+	k.id, k.user_id, u.username, u.role, k.api_key, k.ip_whitelist, k.callback_url,
+	k.is_active, k.rotated_at, k.created_at, k.updated_at`
+
+func scanResellerKey(row interface{ Scan(...interface{}) error }) (*model.ResellerKey, error) {
+	var key model.ResellerKey
+	var role string
+	err := row.Scan(
+		&key.ID, &key.UserID, &key.Username, &role, &key.APIKey, pq.Array(&key.IPWhitelist), &key.CallbackURL,
+		&key.IsActive, &key.RotatedAt, &key.CreatedAt, &key.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.Role = model.ParseUserRole(role)
+	return &key, nil
+}
+
+// Issue stores a new API key for the user, replacing any previous one.
+func (repo *ResellerRepository) Issue(ctx context.Context, userID int, apiKey string) (*model.ResellerKey, error) {
+	query := `
+		INSERT INTO reseller_keys (user_id, api_key, rotated_at, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET api_key = EXCLUDED.api_key, rotated_at = NOW(), updated_at = NOW()`
+
+	if _, err := repo.DB.ExecContext(ctx, query, userID, apiKey); err != nil {
+		log.Printf("Issue ResellerKey error: %v", err)
+		return nil, err
+	}
+	return repo.GetByUserID(ctx, userID)
+}
+
+func (repo *ResellerRepository) GetByUserID(ctx context.Context, userID int) (*model.ResellerKey, error) {
+	query := `
+		SELECT ` + resellerKeyColumns + `
+		FROM reseller_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.user_id = $1`
+
+	key, err := scanResellerKey(repo.DB.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByUserID ResellerKey error: %v", err)
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetByUsername returns the key of an active user, or nil when there is none.
+func (repo *ResellerRepository) GetByUsername(ctx context.Context, username string) (*model.ResellerKey, error) {
+	query := `
+		SELECT ` + resellerKeyColumns + `
+		FROM reseller_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE u.username = $1 AND u.status = $2`
+
+	key, err := scanResellerKey(repo.DB.QueryRowContext(ctx, query, username, model.UserStatusActive))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByUsername ResellerKey error: %v", err)
+		return nil, err
+	}
+	return key, nil
+}
+
+func (repo *ResellerRepository) UpdateSettings(ctx context.Context, userID int, settings model.UpdateResellerSettings) (*model.ResellerKey, error) {
+	query := `
+		UPDATE reseller_keys
+		SET ip_whitelist = $1, callback_url = NULLIF($2, ''), updated_at = NOW()
+		WHERE user_id = $3`
+
+	callbackURL := ""
+	if settings.CallbackURL != nil {
+		callbackURL = *settings.CallbackURL
+	}
+	whitelist := settings.IPWhitelist
+	if whitelist == nil {
+		whitelist = []string{}
+	}
+
+	res, err := repo.DB.ExecContext(ctx, query, pq.Array(whitelist), callbackURL, userID)
+	if err != nil {
+		log.Printf("UpdateSettings ResellerKey error: %v", err)
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return nil, err
+	}
+	return repo.GetByUserID(ctx, userID)
+}
+
+// SetActive enables or disables a reseller's API access.
+func (repo *ResellerRepository) SetActive(ctx context.Context, userID int, active bool) (bool, error) {
+	res, err := repo.DB.ExecContext(ctx, `
+		UPDATE reseller_keys SET is_active = $1, updated_at = NOW() WHERE user_id = $2`, active, userID)
+	if err != nil {
+		log.Printf("SetActive ResellerKey error: %v", err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetPriceList lists prepaid products at the role's price. A product is
+// available while one of its provider SKUs can take an order right now.
+func (repo *ResellerRepository) GetPriceList(ctx context.Context, role model.UserRole) ([]model.H2HProduct, error) {
+	query := `
+		SELECT p.id, p.name, c.name, p.` + priceColumn(role) + `,
+			p.status = 'active' AND EXISTS (
+				SELECT 1 FROM provider_products pp
+				WHERE pp.product_id = p.id
+				  AND pp.is_available = true
+				  AND pp.is_maintenance = false
+				  AND NOT ` + inCutOff + `
+			)
+		FROM products p
+		JOIN categories c ON c.id = p.category_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM provider_products pp WHERE pp.product_id = p.id AND pp.is_postpaid
+		)
+		ORDER BY c.name, p.` + priceColumn(role) + `, p.id`
+
+	rows, err := repo.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("GetPriceList error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []model.H2HProduct
+	for rows.Next() {
+		var product model.H2HProduct
+		if err := rows.Scan(&product.ProductID, &product.Name, &product.Category, &product.Price, &product.Available); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
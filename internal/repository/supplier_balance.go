@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type SupplierBalanceRepository struct {
+	DB *sql.DB
+}
+
+func NewSupplierBalanceRepository(db *sql.DB) *SupplierBalanceRepository {
+	return &SupplierBalanceRepository{DB: db}
+}
+
+const supplierBalanceColumns = `id, provider_slug, balance, source, reference, created_at`
+
+func scanSupplierBalance(row interface{ Scan(...interface{}) error }) (*model.SupplierBalance, error) {
+	var b model.SupplierBalance
+	if err := row.Scan(&b.ID, &b.Provider, &b.Balance, &b.Source, &b.Reference, &b.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (repo *SupplierBalanceRepository) Create(ctx context.Context, provider string, balance int64, source, reference string) (*model.SupplierBalance, error) {
+	query := `
+		INSERT INTO supplier_balances (provider_slug, balance, source, reference, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NOW())
+		RETURNING ` + supplierBalanceColumns
+
+	snapshot, err := scanSupplierBalance(repo.DB.QueryRowContext(ctx, query, provider, balance, source, reference))
+	if err != nil {
+		log.Printf("Create SupplierBalance error: %v", err)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Latest returns the newest snapshot for a provider, or nil when there is none.
+func (repo *SupplierBalanceRepository) Latest(ctx context.Context, provider string) (*model.SupplierBalance, error) {
+	query := `
+		SELECT ` + supplierBalanceColumns + `
+		FROM supplier_balances
+		WHERE provider_slug = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`
+
+	snapshot, err := scanSupplierBalance(repo.DB.QueryRowContext(ctx, query, provider))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Latest SupplierBalance error: %v", err)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (repo *SupplierBalanceRepository) GetAll(ctx context.Context, filter model.FilterSupplierBalance) ([]model.SupplierBalance, int, error) {
+	var totalCount int
+	err := repo.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM supplier_balances WHERE ($1 = '' OR provider_slug = $1)`,
+		filter.Provider,
+	).Scan(&totalCount)
+	if err != nil {
+		log.Printf("GetAll SupplierBalances count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + supplierBalanceColumns + `
+		FROM supplier_balances
+		WHERE ($1 = '' OR provider_slug = $1)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := repo.DB.QueryContext(ctx, query, filter.Provider, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Printf("GetAll SupplierBalances error: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var snapshots []model.SupplierBalance
+	for rows.Next() {
+		b, err := scanSupplierBalance(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		snapshots = append(snapshots, *b)
+	}
+	return snapshots, totalCount, rows.Err()
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type SyncRunRepository struct {
+	DB *sql.DB
+}
+
+func NewSyncRunRepository(db *sql.DB) *SyncRunRepository {
+	return &SyncRunRepository{DB: db}
+}
+
+const syncRunColumns = `
+	id, provider, trigger, status, inserted, updated, skipped, failed, removed,
+	failures, removals, error, started_at, finished_at`
+
+func scanSyncRun(row interface{ Scan(...interface{}) error }) (*model.SyncRun, error) {
+	var run model.SyncRun
+	var failures, removals []byte
+	err := row.Scan(
+		&run.ID, &run.Provider, &run.Trigger, &run.Status, &run.Inserted, &run.Updated,
+		&run.Skipped, &run.Failed, &run.Removed, &failures, &removals, &run.Error,
+		&run.StartedAt, &run.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(failures, &run.Failures); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(removals, &run.Removals); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// TryLock takes a session advisory lock for the provider's sync on a connection
+// of its own and reports false when another run holds it. The lock lasts until
+// release is called, or until the connection drops if the process dies.
+func (repo *SyncRunRepository) TryLock(ctx context.Context, provider string) (release func(), ok bool, err error) {
+	conn, err := repo.DB.Conn(ctx)
+	if err != nil {
+		log.Printf("TryLock SyncRun error: %v", err)
+		return nil, false, err
+	}
+
+	key := "product-sync:" + provider
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, key).Scan(&ok); err != nil {
+		log.Printf("TryLock SyncRun error: %v", err)
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release = func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, key); err != nil {
+			log.Printf("Release SyncRun lock error: %v", err)
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+func (repo *SyncRunRepository) Start(ctx context.Context, provider, trigger string) (*model.SyncRun, error) {
+	query := `
+		INSERT INTO sync_runs (provider, trigger, status, started_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING ` + syncRunColumns
+
+	run, err := scanSyncRun(repo.DB.QueryRowContext(ctx, query, provider, trigger, model.SyncStatusRunning))
+	if err != nil {
+		log.Printf("Start SyncRun error: %v", err)
+		return nil, err
+	}
+	return run, nil
+}
+
+// Finish stores the outcome of a run. A non-empty errMessage marks it failed.
+func (repo *SyncRunRepository) Finish(ctx context.Context, id int, report model.SyncReport, errMessage string) (*model.SyncRun, error) {
+	status := model.SyncStatusSuccess
+	if errMessage != "" {
+		status = model.SyncStatusFailed
+	}
+	if report.Failures == nil {
+		report.Failures = []model.SyncFailure{}
+	}
+	if report.Removals == nil {
+		report.Removals = []model.SyncRemoval{}
+	}
+	failuresJSON, err := json.Marshal(report.Failures)
+	if err != nil {
+		return nil, err
+	}
+	removalsJSON, err := json.Marshal(report.Removals)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE sync_runs
+		SET status = $1, inserted = $2, updated = $3, skipped = $4, failed = $5, removed = $6,
+			failures = $7, removals = $8, error = NULLIF($9, ''), finished_at = NOW()
+		WHERE id = $10
+		RETURNING ` + syncRunColumns
+
+	run, err := scanSyncRun(repo.DB.QueryRowContext(ctx, query,
+		status, report.Inserted, report.Updated, report.Skipped, report.Failed, report.Removed,
+		failuresJSON, removalsJSON, errMessage, id,
+	))
+	if err != nil {
+		log.Printf("Finish SyncRun error: %v", err)
+		return nil, err
+	}
+	return run, nil
+}
+
+func (repo *SyncRunRepository) GetAll(ctx context.Context, limit, offset int) ([]model.SyncRun, int, error) {
+	var totalCount int
+	if err := repo.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM sync_runs`).Scan(&totalCount); err != nil {
+		log.Printf("GetAll SyncRuns count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + syncRunColumns + `
+		FROM sync_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := repo.DB.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		log.Printf("GetAll SyncRuns error: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var runs []model.SyncRun
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, totalCount, rows.Err()
+}
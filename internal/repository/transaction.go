@@ -0,0 +1,571 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type TransactionRepository struct {
+	DB *sql.DB
+}
+
+func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+	return &TransactionRepository{DB: db}
+}
+
+const transactionColumns = `
+	t.id, t.ref_id, t.username, t.product_id, p.name, t.provider_product_id,
+	t.provider_code, t.provider_slug, t.provider_ref_id, t.customer_no, t.price, t.cost_price, t.fee, t.total,
+	t.payment_method, t.payment_status, t.payment_reference, t.payment_url, t.status,
+	t.rc, t.sn, t.message, t.buyer_last_saldo, t.status_checks, t.escalated_at, t.escalation_reason,
+	t.bill_ref_id, t.reseller_ref_id, t.created_at, t.updated_at`
+
+func scanTransaction(row interface{ Scan(...interface{}) error }) (*model.Transaction, error) {
+	var trx model.Transaction
+	err := row.Scan(
+		&trx.ID, &trx.RefID, &trx.Username, &trx.ProductID, &trx.ProductName, &trx.ProviderProductID,
+		&trx.ProviderCode, &trx.ProviderSlug, &trx.ProviderRefID, &trx.CustomerNo, &trx.Price, &trx.CostPrice, &trx.Fee, &trx.Total,
+		&trx.PaymentMethod, &trx.PaymentStatus, &trx.PaymentReference, &trx.PaymentUrl, &trx.Status,
+		&trx.RC, &trx.SN, &trx.Message, &trx.BuyerLastSaldo, &trx.StatusChecks, &trx.EscalatedAt, &trx.EscalationReason,
+		&trx.BillRefID, &trx.ResellerRefID, &trx.CreatedAt, &trx.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &trx, nil
+}
+
+// GetProductForOrder prices an active product for the buyer's role and picks
+// its cheapest available provider SKU, preferring SKUs outside their cut-off.
+// Postpaid tells whether the SKU is a bill payment that needs an inquiry.
+// DuplicateWindowMinutes is the category's duplicate window, if it sets one.
+func (repo *TransactionRepository) GetProductForOrder(ctx context.Context, productID int, role model.UserRole) (*model.OrderProduct, error) {
+	query := `
+		SELECT p.id, p.name, p.` + priceColumn(role) + `, pp.id, pp.provider_code, pr.slug, pp.cost_price,
+			pp.is_postpaid, ` + inCutOff + ` AS in_cut_off, c.duplicate_window_minutes
+		FROM products p
+		JOIN provider_products pp ON pp.product_id = p.id
+		LEFT JOIN categories c ON c.id = p.category_id
+		JOIN providers pr ON pr.id = pp.provider_id
+		WHERE p.id = $1
+		  AND p.status = 'active'
+		  AND pp.is_available = true
+		  AND pp.is_maintenance = false
+		ORDER BY in_cut_off ASC, pp.cost_price ASC
+		LIMIT 1`
+
+	var op model.OrderProduct
+	err := repo.DB.QueryRowContext(ctx, query, productID).Scan(
+		&op.ProductID, &op.ProductName, &op.Price, &op.ProviderProductID,
+		&op.ProviderCode, &op.ProviderSlug, &op.CostPrice, &op.Postpaid, &op.InCutOff,
+		&op.DuplicateWindowMinutes,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetProductForOrder error: %v", err)
+		return nil, err
+	}
+	return &op, nil
+}
+
+// LockOrderKey takes a transaction-scoped advisory lock on the user, product
+// and customer number, so concurrent identical orders are checked one by one.
+// exec must be a *sql.Tx; the lock is released when it ends.
+func (repo *TransactionRepository) LockOrderKey(ctx context.Context, exec DBTX, username string, productID int, customerNo string) error {
+	key := fmt.Sprintf("%s:%d:%s", username, productID, customerNo)
+	if _, err := exec.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+		log.Printf("LockOrderKey error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// CountRecentOrders counts the user's paid, live (Pending or Sukses) orders
+// for the same product and customer number placed since the given time.
+// Unpaid gateway checkouts are left out so an abandoned invoice does not block
+// a retry.
+func (repo *TransactionRepository) CountRecentOrders(ctx context.Context, exec DBTX, username string, productID int, customerNo string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE username = $1 AND product_id = $2 AND customer_no = $3
+		  AND created_at >= $4 AND status IN ($5, $6) AND payment_status = $7`
+
+	var count int
+	err := exec.QueryRowContext(ctx, query, username, productID, customerNo, since,
+		model.TransactionStatusPending, model.TransactionStatusSuccess, model.PaymentStatusPaid,
+	).Scan(&count)
+	if err != nil {
+		log.Printf("CountRecentOrders error: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetProviderCandidates lists the provider SKUs that can fulfil a product right
+// now for at most maxCost, cheapest first. SKUs inside their cut-off window are
+// left out.
+func (repo *TransactionRepository) GetProviderCandidates(ctx context.Context, productID, maxCost int) ([]model.ProviderCandidate, error) {
+	query := `
+		SELECT pp.id, pp.provider_code, pr.slug, pp.cost_price
+		FROM provider_products pp
+		JOIN providers pr ON pr.id = pp.provider_id
+		WHERE pp.product_id = $1
+		  AND pp.cost_price <= $2
+		  AND pp.is_available = true
+		  AND pp.is_maintenance = false
+		  AND NOT ` + inCutOff + `
+		ORDER BY pp.cost_price ASC, pp.id ASC`
+
+	rows, err := repo.DB.QueryContext(ctx, query, productID, maxCost)
+	if err != nil {
+		log.Printf("GetProviderCandidates error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []model.ProviderCandidate
+	for rows.Next() {
+		var cand model.ProviderCandidate
+		if err := rows.Scan(&cand.ProviderProductID, &cand.ProviderCode, &cand.ProviderSlug, &cand.CostPrice); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, cand)
+	}
+	return candidates, rows.Err()
+}
+
+func (repo *TransactionRepository) Create(ctx context.Context, exec DBTX, trx *model.Transaction) error {
+	query := `
+		INSERT INTO transactions (
+			ref_id, username, product_id, provider_product_id, provider_code, provider_slug,
+			customer_no, price, cost_price, fee, total, payment_method, payment_status,
+			payment_reference, payment_url, status, bill_ref_id, reseller_ref_id, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NOW(), NOW()
+		) RETURNING id, created_at, updated_at`
+
+	err := exec.QueryRowContext(ctx, query,
+		trx.RefID, trx.Username, trx.ProductID, trx.ProviderProductID, trx.ProviderCode, trx.ProviderSlug,
+		trx.CustomerNo, trx.Price, trx.CostPrice, trx.Fee, trx.Total, trx.PaymentMethod, trx.PaymentStatus,
+		trx.PaymentReference, trx.PaymentUrl, trx.Status, trx.BillRefID, trx.ResellerRefID,
+	).Scan(&trx.ID, &trx.CreatedAt, &trx.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		log.Printf("Create Transaction error: %v", err)
+	}
+	return err
+}
+
+func (repo *TransactionRepository) GetByRefID(ctx context.Context, refID string) (*model.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE t.ref_id = $1`
+
+	trx, err := scanTransaction(repo.DB.QueryRowContext(ctx, query, refID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByRefID Transaction error: %v", err)
+		return nil, err
+	}
+	return trx, nil
+}
+
+// GetByResellerRefID finds a reseller's order by the ref_id they sent.
+func (repo *TransactionRepository) GetByResellerRefID(ctx context.Context, username, resellerRefID string) (*model.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE t.username = $1 AND t.reseller_ref_id = $2`
+
+	trx, err := scanTransaction(repo.DB.QueryRowContext(ctx, query, username, resellerRefID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByResellerRefID Transaction error: %v", err)
+		return nil, err
+	}
+	return trx, nil
+}
+
+// GetLatestByCustomerNo returns the user's most recent order for a customer
+// number.
+func (repo *TransactionRepository) GetLatestByCustomerNo(ctx context.Context, username, customerNo string) (*model.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE t.username = $1 AND t.customer_no = $2
+		ORDER BY t.created_at DESC
+		LIMIT 1`
+
+	trx, err := scanTransaction(repo.DB.QueryRowContext(ctx, query, username, customerNo))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetLatestByCustomerNo Transaction error: %v", err)
+		return nil, err
+	}
+	return trx, nil
+}
+
+func (repo *TransactionRepository) GetAll(ctx context.Context, filter model.FilterTransaction) ([]model.Transaction, int, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM transactions t
+		WHERE ($1 = '' OR t.ref_id ILIKE '%' || $1 || '%' OR t.customer_no ILIKE '%' || $1 || '%')
+		  AND ($2 = '' OR t.status = $2)
+		  AND ($3 = '' OR t.username = $3)
+	`
+
+	var totalCount int
+	err := repo.DB.QueryRowContext(ctx, countQuery, filter.Search, filter.Status, filter.Username).Scan(&totalCount)
+	if err != nil {
+		log.Printf("GetAll Transactions count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE ($1 = '' OR t.ref_id ILIKE '%' || $1 || '%' OR t.customer_no ILIKE '%' || $1 || '%')
+		  AND ($2 = '' OR t.status = $2)
+		  AND ($3 = '' OR t.username = $3)
+		ORDER BY t.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := repo.DB.QueryContext(ctx, query, filter.Search, filter.Status, filter.Username, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Printf("GetAll Transactions error: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var transactions []model.Transaction
+	for rows.Next() {
+		trx, err := scanTransaction(rows)
+		if err != nil {
+			log.Printf("Scan Transaction error: %v", err)
+			continue
+		}
+		transactions = append(transactions, *trx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, totalCount, nil
+}
+
+// FinalizeProviderResult moves a Pending order to its final status. It reports
+// false when the order was already finalized so callers can ignore replays.
+func (repo *TransactionRepository) FinalizeProviderResult(ctx context.Context, refID string, result model.TransactionProviderResult) (bool, error) {
+	query := `
+		UPDATE transactions
+		SET status = $1, rc = $2, sn = NULLIF($3, ''), message = $4,
+			buyer_last_saldo = $5, updated_at = NOW()
+		WHERE ref_id = $6 AND status = $7`
+
+	res, err := repo.DB.ExecContext(ctx, query,
+		result.Status, result.RC, result.SN, result.Message, result.BuyerLastSaldo,
+		refID, model.TransactionStatusPending,
+	)
+	if err != nil {
+		log.Printf("FinalizeProviderResult Transaction error: %v", err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// SetPaymentInvoice stores the gateway invoice opened for an order.
+func (repo *TransactionRepository) SetPaymentInvoice(ctx context.Context, exec DBTX, refID, reference, url string) error {
+	query := `
+		UPDATE transactions
+		SET payment_reference = $1, payment_url = $2, updated_at = NOW()
+		WHERE ref_id = $3`
+
+	_, err := exec.ExecContext(ctx, query, reference, url, refID)
+	if err != nil {
+		log.Printf("SetPaymentInvoice Transaction error: %v", err)
+	}
+	return err
+}
+
+// UpdatePaymentStatus moves payment_status from one value to another and reports
+// false when the order was not in the expected state.
+func (repo *TransactionRepository) UpdatePaymentStatus(ctx context.Context, exec DBTX, refID, from, to string) (bool, error) {
+	query := `
+		UPDATE transactions
+		SET payment_status = $1, updated_at = NOW()
+		WHERE ref_id = $2 AND payment_status = $3`
+
+	res, err := exec.ExecContext(ctx, query, to, refID, from)
+	if err != nil {
+		log.Printf("UpdatePaymentStatus Transaction error: %v", err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// StartAttempt records a purchase attempt and points the Pending order at the
+// chosen provider SKU in one SQL transaction. expected is the attempt the
+// caller decided from, nil for the first one. It reports false when the order
+// is no longer Pending or has already moved past expected, i.e. someone else
+// routed it meanwhile.
+func (repo *TransactionRepository) StartAttempt(ctx context.Context, attempt *model.TransactionAttempt, expected *string) (bool, error) {
+	started := false
+	err := WithTransaction(ctx, repo.DB, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE transactions
+			SET provider_product_id = $1, provider_code = $2, provider_slug = $3,
+				provider_ref_id = $4, cost_price = $5,
+				status_checks = 0, next_status_check_at = NULL, updated_at = NOW()
+			WHERE ref_id = $6 AND status = $7 AND provider_ref_id IS NOT DISTINCT FROM $8`,
+			attempt.ProviderProductID, attempt.ProviderCode, attempt.ProviderSlug,
+			attempt.ProviderRefID, attempt.CostPrice, attempt.RefID, model.TransactionStatusPending, expected,
+		)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil || affected != 1 {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO transaction_attempts (
+				ref_id, provider_ref_id, provider_slug, provider_product_id, provider_code,
+				cost_price, status, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+			RETURNING id, created_at, updated_at`,
+			attempt.RefID, attempt.ProviderRefID, attempt.ProviderSlug, attempt.ProviderProductID,
+			attempt.ProviderCode, attempt.CostPrice, model.TransactionStatusPending,
+		).Scan(&attempt.ID, &attempt.CreatedAt, &attempt.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		started = true
+		return nil
+	})
+	if err != nil {
+		log.Printf("StartAttempt Transaction error: %v", err)
+	}
+	return started, err
+}
+
+func (repo *TransactionRepository) UpdateAttempt(ctx context.Context, providerRefID, status, rc, message string) error {
+	query := `
+		UPDATE transaction_attempts
+		SET status = $1, rc = NULLIF($2, ''), message = NULLIF($3, ''), updated_at = NOW()
+		WHERE provider_ref_id = $4`
+
+	_, err := repo.DB.ExecContext(ctx, query, status, rc, message, providerRefID)
+	if err != nil {
+		log.Printf("UpdateAttempt Transaction error: %v", err)
+	}
+	return err
+}
+
+// GetAttemptByProviderRefID finds the attempt a provider callback refers to.
+func (repo *TransactionRepository) GetAttemptByProviderRefID(ctx context.Context, providerRefID string) (*model.TransactionAttempt, error) {
+	query := `
+		SELECT id, ref_id, provider_ref_id, provider_slug, provider_product_id, provider_code,
+			cost_price, status, rc, message, created_at, updated_at
+		FROM transaction_attempts
+		WHERE provider_ref_id = $1`
+
+	var a model.TransactionAttempt
+	err := repo.DB.QueryRowContext(ctx, query, providerRefID).Scan(
+		&a.ID, &a.RefID, &a.ProviderRefID, &a.ProviderSlug, &a.ProviderProductID, &a.ProviderCode,
+		&a.CostPrice, &a.Status, &a.RC, &a.Message, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetAttemptByProviderRefID error: %v", err)
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (repo *TransactionRepository) GetAttempts(ctx context.Context, refID string) ([]model.TransactionAttempt, error) {
+	query := `
+		SELECT id, ref_id, provider_ref_id, provider_slug, provider_product_id, provider_code,
+			cost_price, status, rc, message, created_at, updated_at
+		FROM transaction_attempts
+		WHERE ref_id = $1
+		ORDER BY id`
+
+	rows, err := repo.DB.QueryContext(ctx, query, refID)
+	if err != nil {
+		log.Printf("GetAttempts Transaction error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []model.TransactionAttempt
+	for rows.Next() {
+		var a model.TransactionAttempt
+		err := rows.Scan(
+			&a.ID, &a.RefID, &a.ProviderRefID, &a.ProviderSlug, &a.ProviderProductID, &a.ProviderCode,
+			&a.CostPrice, &a.Status, &a.RC, &a.Message, &a.CreatedAt, &a.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// GetStuckPending returns paid orders that are still Pending, were created
+// before olderThan and are due for a status check. Orders that never reached
+// a provider have no provider_ref_id and are returned too.
+func (repo *TransactionRepository) GetStuckPending(ctx context.Context, olderThan time.Time, limit int) ([]model.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE t.status = $1
+		  AND t.payment_status = $2
+		  AND t.created_at <= $3
+		  AND (t.next_status_check_at IS NULL OR t.next_status_check_at <= NOW())
+		ORDER BY t.next_status_check_at NULLS FIRST, t.created_at
+		LIMIT $4`
+
+	rows, err := repo.DB.QueryContext(ctx, query,
+		model.TransactionStatusPending, model.PaymentStatusPaid, olderThan, limit,
+	)
+	if err != nil {
+		log.Printf("GetStuckPending Transaction error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []model.Transaction
+	for rows.Next() {
+		trx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *trx)
+	}
+	return transactions, rows.Err()
+}
+
+// ScheduleStatusCheck counts a status check that left the order Pending and
+// sets when the next one is due.
+func (repo *TransactionRepository) ScheduleStatusCheck(ctx context.Context, refID string, next time.Time) error {
+	query := `
+		UPDATE transactions
+		SET status_checks = status_checks + 1, next_status_check_at = $1
+		WHERE ref_id = $2`
+
+	_, err := repo.DB.ExecContext(ctx, query, next, refID)
+	if err != nil {
+		log.Printf("ScheduleStatusCheck Transaction error: %v", err)
+	}
+	return err
+}
+
+// EscalateConflict flags an order for admins whatever its status. Unlike a
+// stuck-order escalation it stays listed after the order is final.
+func (repo *TransactionRepository) EscalateConflict(ctx context.Context, refID, reason string) error {
+	_, err := repo.DB.ExecContext(ctx, `
+		UPDATE transactions
+		SET escalated_at = NOW(), escalation_reason = $1
+		WHERE ref_id = $2`, reason, refID)
+	if err != nil {
+		log.Printf("EscalateConflict Transaction error: %v", err)
+	}
+	return err
+}
+
+// MarkEscalated flags a Pending order for admin attention and reports false
+// when it was already flagged or is no longer Pending.
+func (repo *TransactionRepository) MarkEscalated(ctx context.Context, refID string) (bool, error) {
+	query := `
+		UPDATE transactions
+		SET escalated_at = NOW()
+		WHERE ref_id = $1 AND status = $2 AND escalated_at IS NULL`
+
+	res, err := repo.DB.ExecContext(ctx, query, refID, model.TransactionStatusPending)
+	if err != nil {
+		log.Printf("MarkEscalated Transaction error: %v", err)
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// GetEscalated lists the escalated orders that are still Pending, plus
+// conflicts flagged by EscalateConflict, oldest first.
+func (repo *TransactionRepository) GetEscalated(ctx context.Context, limit, offset int) ([]model.Transaction, int, error) {
+	var totalCount int
+	err := repo.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM transactions
+		WHERE escalated_at IS NOT NULL AND (status = $1 OR escalation_reason IS NOT NULL)`,
+		model.TransactionStatusPending,
+	).Scan(&totalCount)
+	if err != nil {
+		log.Printf("GetEscalated Transactions count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN products p ON p.id = t.product_id
+		WHERE t.escalated_at IS NOT NULL AND (t.status = $1 OR t.escalation_reason IS NOT NULL)
+		ORDER BY t.created_at ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := repo.DB.QueryContext(ctx, query, model.TransactionStatusPending, limit, offset)
+	if err != nil {
+		log.Printf("GetEscalated Transactions error: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var transactions []model.Transaction
+	for rows.Next() {
+		trx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, *trx)
+	}
+	return transactions, totalCount, rows.Err()
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type WalletRepository struct {
+	DB *sql.DB
+}
+
+func NewWalletRepository(db *sql.DB) *WalletRepository {
+	return &WalletRepository{DB: db}
+}
+
+// LockBalance reads a user's balance with FOR UPDATE so concurrent mutations
+// on the same wallet are serialized until tx ends.
+func (repo *WalletRepository) LockBalance(ctx context.Context, tx *sql.Tx, username string) (int, int64, error) {
+	query := `SELECT id, balance FROM users WHERE username = $1 FOR UPDATE`
+
+	var (
+		userID  int
+		balance int64
+	)
+	err := tx.QueryRowContext(ctx, query, username).Scan(&userID, &balance)
+	if err != nil {
+		return 0, 0, err
+	}
+	return userID, balance, nil
+}
+
+// InsertEntry records a ledger entry. It reports false when an entry for the same
+// reference and direction already exists.
+func (repo *WalletRepository) InsertEntry(ctx context.Context, tx *sql.Tx, entry *model.LedgerEntry) (bool, error) {
+	query := `
+		INSERT INTO ledger_entries (
+			user_id, entry_type, amount, balance_before, balance_after,
+			reference_type, reference_id, description, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, NOW()
+		)
+		ON CONFLICT (reference_type, reference_id, entry_type) DO NOTHING
+		RETURNING id, created_at`
+
+	err := tx.QueryRowContext(ctx, query,
+		entry.UserID, entry.EntryType, entry.Amount, entry.BalanceBefore, entry.BalanceAfter,
+		entry.ReferenceType, entry.ReferenceID, entry.Description,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("InsertEntry Ledger error: %v", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *WalletRepository) UpdateBalance(ctx context.Context, tx *sql.Tx, userID int, balance int64) error {
+	query := `UPDATE users SET balance = $1, updated_at = NOW() WHERE id = $2`
+
+	_, err := tx.ExecContext(ctx, query, balance, userID)
+	if err != nil {
+		log.Printf("UpdateBalance Wallet error: %v", err)
+	}
+	return err
+}
+
+func (repo *WalletRepository) GetBalance(ctx context.Context, username string) (*model.WalletBalance, error) {
+	query := `SELECT id, username, balance FROM users WHERE username = $1`
+
+	var wb model.WalletBalance
+	err := repo.DB.QueryRowContext(ctx, query, username).Scan(&wb.UserID, &wb.Username, &wb.Balance)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetBalance Wallet error: %v", err)
+		return nil, err
+	}
+	return &wb, nil
+}
+
+func (repo *WalletRepository) GetEntries(ctx context.Context, userID, limit, offset int) ([]model.LedgerEntry, int, error) {
+	var totalCount int
+	err := repo.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM ledger_entries WHERE user_id = $1`, userID).Scan(&totalCount)
+	if err != nil {
+		log.Printf("GetEntries Ledger count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, user_id, entry_type, amount, balance_before, balance_after,
+			reference_type, reference_id, description, created_at
+		FROM ledger_entries
+		WHERE user_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := repo.DB.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		log.Printf("GetEntries Ledger error: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []model.LedgerEntry
+	for rows.Next() {
+		var e model.LedgerEntry
+		err := rows.Scan(
+			&e.ID, &e.UserID, &e.EntryType, &e.Amount, &e.BalanceBefore, &e.BalanceAfter,
+			&e.ReferenceType, &e.ReferenceID, &e.Description, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, totalCount, nil
+}
+
+// Audit replays the ledger for a user: totals must add up to the stored balance
+// and every entry must start from the balance the previous entry ended with.
+func (repo *WalletRepository) Audit(ctx context.Context, userID int) (*model.WalletAudit, error) {
+	query := `
+		WITH chain AS (
+			SELECT entry_type, amount, balance_before, balance_after,
+				LAG(balance_after, 1, 0::BIGINT) OVER (ORDER BY id) AS prev_after
+			FROM ledger_entries
+			WHERE user_id = $1
+		)
+		SELECT
+			u.balance,
+			COALESCE(SUM(c.amount) FILTER (WHERE c.entry_type = 'CREDIT'), 0),
+			COALESCE(SUM(c.amount) FILTER (WHERE c.entry_type = 'DEBIT'), 0),
+			COUNT(c.entry_type),
+			COUNT(c.entry_type) FILTER (
+				WHERE c.balance_before <> c.prev_after
+				   OR c.balance_after <> c.balance_before +
+						CASE WHEN c.entry_type = 'CREDIT' THEN c.amount ELSE -c.amount END
+			)
+		FROM users u
+		LEFT JOIN chain c ON true
+		WHERE u.id = $1
+		GROUP BY u.balance`
+
+	audit := model.WalletAudit{UserID: userID}
+	err := repo.DB.QueryRowContext(ctx, query, userID).Scan(
+		&audit.Balance, &audit.TotalCredit, &audit.TotalDebit, &audit.EntryCount, &audit.BrokenEntries,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Audit Wallet error: %v", err)
+		return nil, err
+	}
+
+	audit.LedgerBalance = audit.TotalCredit - audit.TotalDebit
+	audit.Consistent = audit.LedgerBalance == audit.Balance && audit.BrokenEntries == 0
+	return &audit, nil
+}
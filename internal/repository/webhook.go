@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+)
+
+type WebhookRepository struct {
+	DB *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{DB: db}
+}
+
+const webhookDeliveryColumns = `
+	id, transaction_ref_id, username, url, payload, status, attempts,
+	last_status_code, last_error, next_attempt_at, delivered_at, created_at, updated_at`
+
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	err := row.Scan(
+		&d.ID, &d.TransactionRefID, &d.Username, &d.URL, &d.Payload, &d.Status, &d.Attempts,
+		&d.LastStatusCode, &d.LastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Create stores a Pending delivery that the dispatcher will not pick up before
+// nextAttemptAt. It returns nil when the order already has a delivery.
+func (repo *WebhookRepository) Create(ctx context.Context, d *model.WebhookDelivery, nextAttemptAt time.Time) (*model.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (transaction_ref_id, username, url, payload, status, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (transaction_ref_id) DO NOTHING
+		RETURNING ` + webhookDeliveryColumns
+
+	created, err := scanWebhookDelivery(repo.DB.QueryRowContext(ctx, query,
+		d.TransactionRefID, d.Username, d.URL, d.Payload, model.WebhookStatusPending, nextAttemptAt,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Create WebhookDelivery error: %v", err)
+		return nil, err
+	}
+	return created, nil
+}
+
+// ClaimDue returns Pending deliveries whose retry time has come and pushes
+// their next attempt to leaseUntil, so a slow send is not picked up twice.
+func (repo *WebhookRepository) ClaimDue(ctx context.Context, leaseUntil time.Time, limit int) ([]model.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+
+	rows, err := repo.DB.QueryContext(ctx, query, leaseUntil, model.WebhookStatusPending, limit)
+	if err != nil {
+		log.Printf("ClaimDue WebhookDeliveries error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt logs one POST and moves the delivery on. The delivery row is
+// locked while next picks the new status and retry time from its stored
+// status and attempt count, this attempt included. A Pending delivery is
+// retried at the returned time.
+func (repo *WebhookRepository) RecordAttempt(ctx context.Context, attempt *model.WebhookAttempt, next func(status string, attempts int) (string, time.Time)) error {
+	return WithTransaction(ctx, repo.DB, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO webhook_attempts (delivery_id, status_code, error, duration_ms, manual, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())`,
+			attempt.DeliveryID, attempt.StatusCode, attempt.Error, attempt.DurationMs, attempt.Manual,
+		)
+		if err != nil {
+			log.Printf("RecordAttempt WebhookAttempt error: %v", err)
+			return err
+		}
+
+		var current string
+		var attempts int
+		err = tx.QueryRowContext(ctx, `SELECT status, attempts FROM webhook_deliveries WHERE id = $1 FOR UPDATE`,
+			attempt.DeliveryID).Scan(&current, &attempts)
+		if err != nil {
+			log.Printf("RecordAttempt WebhookDelivery error: %v", err)
+			return err
+		}
+		attempts++
+		status, nextAttemptAt := next(current, attempts)
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = $1, attempts = $2, last_status_code = $3, last_error = $4,
+				next_attempt_at = $5,
+				delivered_at = CASE WHEN $1 = $6 THEN COALESCE(delivered_at, NOW()) ELSE delivered_at END,
+				updated_at = NOW()
+			WHERE id = $7`,
+			status, attempts, attempt.StatusCode, attempt.Error, nextAttemptAt, model.WebhookStatusDelivered, attempt.DeliveryID,
+		)
+		if err != nil {
+			log.Printf("RecordAttempt WebhookDelivery error: %v", err)
+		}
+		return err
+	})
+}
+
+func (repo *WebhookRepository) GetByID(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	d, err := scanWebhookDelivery(repo.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GetByID WebhookDelivery error: %v", err)
+		return nil, err
+	}
+	return d, nil
+}
+
+func (repo *WebhookRepository) GetAttempts(ctx context.Context, deliveryID int) ([]model.WebhookAttempt, error) {
+	query := `
+		SELECT id, delivery_id, status_code, error, duration_ms, manual, created_at
+		FROM webhook_attempts
+		WHERE delivery_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := repo.DB.QueryContext(ctx, query, deliveryID)
+	if err != nil {
+		log.Printf("GetAttempts WebhookAttempts error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []model.WebhookAttempt
+	for rows.Next() {
+		var a model.WebhookAttempt
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.StatusCode, &a.Error, &a.DurationMs, &a.Manual, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+func (repo *WebhookRepository) GetAll(ctx context.Context, filter model.FilterWebhookDelivery) ([]model.WebhookDelivery, int, error) {
+	where := `
+		WHERE ($1 = '' OR transaction_ref_id ILIKE '%' || $1 || '%')
+		  AND ($2 = '' OR status = $2)
+		  AND ($3 = '' OR username = $3)`
+
+	var totalCount int
+	err := repo.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries`+where,
+		filter.Search, filter.Status, filter.Username,
+	).Scan(&totalCount)
+	if err != nil {
+		log.Printf("GetAll WebhookDeliveries count error: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries` + where + `
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5`
+
+	rows, err := repo.DB.QueryContext(ctx, query, filter.Search, filter.Status, filter.Username, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Printf("GetAll WebhookDeliveries error: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, totalCount, rows.Err()
+}
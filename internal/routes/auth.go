@@ -2,18 +2,42 @@ package routes
 
 import (
 	"database/sql"
+	"log"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/wafi04/otomaxv2/internal/config"
 	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/pkg/token"
 )
 
-func AuthRoutes(r *gin.RouterGroup, DB *sql.DB) {
-
+func AuthRoutes(r *gin.RouterGroup, cfg config.Config, DB *sql.DB, redisClient *redis.Client, tokens *token.Manager, auth *middleware.AuthMiddleware) {
+	authRepo := repository.NewAuthRepository(DB)
+	var sender services.OTPSender = services.LogOTPSender{}
+	if cfg.OTP.SenderURL != "" {
+		sender = services.NewHTTPOTPSender(cfg.OTP.SenderURL, cfg.OTP.SenderKey, cfg.OTP.SenderTimeout)
+	} else if cfg.IsProduction() {
+		log.Fatal("OTP_SENDER_URL is required in production, codes would only be logged")
+	}
+	otpService := services.NewOTPService(redisClient, sender, cfg.OTP)
+	authService := services.NewAuthService(authRepo, tokens, otpService)
+	authHandler := handler.NewAuthHandler(authService, cfg.App.SecretKey, cfg.IsProduction())
 
-	categoryGroup := r.Group("/auth")
+	authGroup := r.Group("/auth")
 	{
-		categoryGroup.GET("", handler.GoogleLoginHandler)
-		categoryGroup.GET("/google/callback", handler.GoogleCallbackHandler)
+		authGroup.GET("", authHandler.GoogleLogin)
+		authGroup.GET("/google/callback", authHandler.GoogleCallback)
+		authGroup.GET("/google/link", auth.Authenticate(), authHandler.GoogleLink)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/register", authHandler.Register)
+		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/phone/otp", auth.Authenticate(), authHandler.SendPhoneOTP)
+		authGroup.POST("/phone/verify", auth.Authenticate(), authHandler.VerifyPhone)
+		authGroup.PUT("/pin", auth.Authenticate(), authHandler.SetPIN)
 	}
 
 }
@@ -5,22 +5,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
 	"github.com/wafi04/otomaxv2/internal/repository"
 	"github.com/wafi04/otomaxv2/internal/services"
 )
 
-func CategoryRoutes(r *gin.RouterGroup, DB *sql.DB) {
+func CategoryRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
 	categoryRepo := repository.NewCategoryRepository(DB)
 	categoryService := services.NewCategoryService(categoryRepo)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
+	admin := auth.RequireRole(model.RoleAdmin)
+
 	categoryGroup := r.Group("/categories")
 	{
-		categoryGroup.POST("", categoryHandler.CreateCategory)
+		categoryGroup.POST("", admin, categoryHandler.CreateCategory)
 		categoryGroup.GET("", categoryHandler.GetAllCategories)
-		categoryGroup.GET("/:code", categoryHandler.GetCategoryByCode)
-		categoryGroup.PUT("/:id", categoryHandler.UpdateCategory)
-		categoryGroup.DELETE("/:id", categoryHandler.DeleteCategory)
+		categoryGroup.GET("/:code", auth.Optional(), categoryHandler.GetCategoryByCode)
+		categoryGroup.PUT("/:id", admin, categoryHandler.UpdateCategory)
+		categoryGroup.DELETE("/:id", admin, categoryHandler.DeleteCategory)
 	}
 
 }
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/config"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/integrations/duitku"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/internal/worker"
+)
+
+func DepositRoutes(r *gin.RouterGroup, cfg config.Config, DB *sql.DB, auth *middleware.AuthMiddleware) {
+	duitkuCfg := cfg.PaymentGateway.DuitkuConfig
+
+	walletService := services.NewWalletService(repository.NewWalletRepository(DB))
+	depositRepo := repository.NewDepositRepository(DB)
+	depositService := services.NewDuitkuService(depositRepo, duitku.NewDuitkuService(&cfg), walletService, duitkuCfg.CallbackURL, duitkuCfg.ReturnURL)
+	depositHandler := handler.NewDepositHandler(depositService)
+
+	go worker.NewDepositReconciler(depositService, duitkuCfg.ReconcileInterval, duitkuCfg.ReconcileAfter, duitkuCfg.ReconcileMaxDelay).Start(context.Background())
+
+	idempotency := middleware.NewIdempotencyMiddleware(repository.NewIdempotencyRepository(DB), cfg.Idempotency.KeyTTL)
+
+	depositGroup := r.Group("/deposits")
+	{
+		depositGroup.POST("", auth.Authenticate(), idempotency.Handle("deposits.create"), depositHandler.Create)
+		depositGroup.GET("", auth.RequireRole(model.RoleAdmin), depositHandler.GetAll)
+		depositGroup.POST("/callback/duitku", depositHandler.DuitkuCallback)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+// gatewayRoutes registers the text command gateway fed by chat and SMS relays.
+func gatewayRoutes(r *gin.RouterGroup, auth *middleware.AuthMiddleware, gatewayService *services.GatewayService, relaySecret string) {
+	gatewayHandler := handler.NewGatewayHandler(gatewayService, relaySecret)
+
+	r.POST("/gateway/message", gatewayHandler.Message)
+	r.PUT("/products/:id/keyword", auth.RequireRole(model.RoleAdmin), gatewayHandler.SetKeyword)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/config"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+func MarkupRuleRoutes(r *gin.RouterGroup, cfg config.Config, DB *sql.DB, auth *middleware.AuthMiddleware) {
+	markupRepo := repository.NewMarkupRuleRepository(DB)
+	pricingService := services.NewPricingService(markupRepo, cfg.Pricing)
+	markupHandler := handler.NewMarkupRuleHandler(pricingService)
+
+	markupGroup := r.Group("/markup-rules", auth.RequireRole(model.RoleAdmin))
+	{
+		markupGroup.POST("", markupHandler.Create)
+		markupGroup.GET("", markupHandler.GetAll)
+		markupGroup.POST("/preview", markupHandler.Preview)
+		markupGroup.PUT("/:id", markupHandler.Update)
+		markupGroup.DELETE("/:id", markupHandler.Delete)
+	}
+}
@@ -5,23 +5,27 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
 	"github.com/wafi04/otomaxv2/internal/repository"
 	"github.com/wafi04/otomaxv2/internal/services"
 )
 
-func MethodRoutes(r *gin.RouterGroup, DB *sql.DB) {
+func MethodRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
 	methodRepo := repository.NewMethodRepository(DB)
 	methodService := services.NewMethodService(methodRepo)
 	methodHandler := handler.NewMethodHandler(methodService)
 
+	admin := auth.RequireRole(model.RoleAdmin)
+
 	categoryGroup := r.Group("/method")
 	{
-		categoryGroup.POST("", methodHandler.Create)
+		categoryGroup.POST("", admin, methodHandler.Create)
 		categoryGroup.GET("", methodHandler.GetAll)
 		categoryGroup.GET("/groub", methodHandler.GetByGrub)
 
 		// categoryGroup.GET("/:id", methodHandler.GetSubCategoryByID)
-		categoryGroup.PUT("/:id", methodHandler.Update)
-		categoryGroup.DELETE("/:id", methodHandler.Delete)
+		categoryGroup.PUT("/:id", admin, methodHandler.Update)
+		categoryGroup.DELETE("/:id", admin, methodHandler.Delete)
 	}
 }
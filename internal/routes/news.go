@@ -5,21 +5,25 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
 	"github.com/wafi04/otomaxv2/internal/repository"
 	"github.com/wafi04/otomaxv2/internal/services"
 )
 
-func NewsRoutes(r *gin.RouterGroup, DB *sql.DB) {
+func NewsRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
 	newsRepo := repository.NewNewsRepository(DB)
 	newsService := services.NewNewsService(newsRepo)
 	newsHandler := handler.NewNewsHandler(newsService)
 
+	admin := auth.RequireRole(model.RoleAdmin)
+
 	categoryGroup := r.Group("/news")
 	{
-		categoryGroup.POST("", newsHandler.Create)
+		categoryGroup.POST("", admin, newsHandler.Create)
 		categoryGroup.GET("", newsHandler.GetAll)
 		// categoryGroup.GET("/:id", newsHandler.GetSubCategoryByID)
-		categoryGroup.PUT("/:id", newsHandler.Update)
-		categoryGroup.DELETE("/:id", newsHandler.Delete)
+		categoryGroup.PUT("/:id", admin, newsHandler.Update)
+		categoryGroup.DELETE("/:id", admin, newsHandler.Delete)
 	}
 }
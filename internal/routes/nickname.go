@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/wafi04/otomaxv2/internal/config"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/integrations/nickname"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+func NicknameRoutes(r *gin.RouterGroup, cfg config.Config, DB *sql.DB, redisClient *redis.Client) {
+	digiService := digiflazz.NewDigiflazzService(digiflazz.DigiConfig{
+		DigiKey:      cfg.Digiflazz.DigiKey,
+		DigiUsername: cfg.Digiflazz.DigiUsername,
+		CallbackURL:  cfg.Digiflazz.CallbackURL,
+	})
+
+	checkers := nickname.Chain{digiflazz.NewNicknameChecker(digiService)}
+	if cfg.Nickname.CheckerURL != "" {
+		checkers = append(checkers, nickname.NewHTTPChecker(cfg.Nickname.CheckerURL, cfg.Nickname.CheckerKey, cfg.Nickname.Timeout))
+	}
+
+	nicknameService := services.NewNicknameService(
+		repository.NewCategoryRepository(DB),
+		checkers,
+		redisClient,
+		cfg.Nickname.CacheTTL,
+		cfg.Nickname.MissTTL,
+	)
+	nicknameHandler := handler.NewNicknameHandler(nicknameService)
+
+	handlers := []gin.HandlerFunc{nicknameHandler.Check}
+	if cfg.Nickname.RateLimit > 0 {
+		limiter := services.NewAttemptLimiter(redisClient, "nickname", cfg.Nickname.RateLimit, cfg.Nickname.RateWindow)
+		handlers = append([]gin.HandlerFunc{middleware.RateLimit(limiter)}, handlers...)
+	}
+	r.POST("/categories/:code/nickname", handlers...)
+}
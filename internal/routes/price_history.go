@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+func PriceHistoryRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
+	priceHistoryService := services.NewPriceHistoryService(repository.NewPriceHistoryRepository(DB))
+	priceHistoryHandler := handler.NewPriceHistoryHandler(priceHistoryService)
+
+	priceHistoryGroup := r.Group("/price-history", auth.RequireRole(model.RoleAdmin))
+	{
+		priceHistoryGroup.GET("/products/:productId", priceHistoryHandler.GetByProduct)
+		priceHistoryGroup.GET("/alerts", priceHistoryHandler.GetAlerts)
+	}
+}
@@ -5,17 +5,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
 	"github.com/wafi04/otomaxv2/internal/repository"
 	"github.com/wafi04/otomaxv2/internal/services"
 )
 
-func ProductRoutes(r *gin.RouterGroup, DB *sql.DB) {
+func ProductRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
 	productRepo := repository.NewProductRepository(DB)
 	productService := services.NewProductService(productRepo)
 	productHandler := handler.NewProductHandler(productService)
 
 	productGroup := r.Group("/products")
 	{
-		productGroup.GET("", productHandler.GetAll)
+		productGroup.GET("", auth.Optional(), productHandler.GetAll)
 	}
 }
@@ -1,27 +1,44 @@
 package routes
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/config"
 	"github.com/wafi04/otomaxv2/internal/handler"
 	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
 	"github.com/wafi04/otomaxv2/internal/services/productexternal"
+	"github.com/wafi04/otomaxv2/internal/worker"
 )
 
-func ProductExternalRoutes(r *gin.RouterGroup, cfg config.Config, db *sql.DB) {
+func ProductExternalRoutes(r *gin.RouterGroup, cfg config.Config, db *sql.DB, auth *middleware.AuthMiddleware) {
 	digiService := digiflazz.NewDigiflazzService(digiflazz.DigiConfig{
 		DigiKey:      cfg.Digiflazz.DigiKey,
 		DigiUsername: cfg.Digiflazz.DigiUsername,
+		CallbackURL:  cfg.Digiflazz.CallbackURL,
 	})
 
 	productExternalService := productexternal.NewProductExternal(digiService, db)
-	productExternalHandler := handler.NewProductExternalHandler(productExternalService)
-	syncProduct := r.Group("/sync/product")
+	pricingService := services.NewPricingService(repository.NewMarkupRuleRepository(db), cfg.Pricing)
+	syncService := services.NewProductSyncService(
+		digiService,
+		productExternalService,
+		pricingService,
+		repository.NewSyncRunRepository(db),
+		cfg.Sync.PriceAlertPercent,
+	)
+	productExternalHandler := handler.NewProductExternalHandler(syncService)
 
+	go worker.NewProductSyncScheduler(syncService, cfg.Sync.Interval).Start(context.Background())
+
+	syncProduct := r.Group("/sync/product", auth.RequireRole(model.RoleAdmin))
 	{
-		syncProduct.GET("/digiflazz", productExternalHandler.GetByDigiflazz)
+		syncProduct.POST("/digiflazz", productExternalHandler.Sync)
+		syncProduct.GET("/runs", productExternalHandler.GetRuns)
 	}
-
 }
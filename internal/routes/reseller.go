@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+// resellerRoutes registers key management for resellers and the signed
+// host-to-host API. It shares the order services built in TransactionRoutes.
+func resellerRoutes(r *gin.RouterGroup, auth *middleware.AuthMiddleware, resellerService *services.ResellerService, webhookService *services.WebhookService) {
+	resellerHandler := handler.NewResellerHandler(resellerService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	resellerGroup := r.Group("/reseller", auth.Authenticate())
+	{
+		resellerGroup.POST("/key", resellerHandler.IssueKey)
+		resellerGroup.GET("/settings", resellerHandler.GetSettings)
+		resellerGroup.PUT("/settings", resellerHandler.UpdateSettings)
+	}
+	r.PATCH("/resellers/:userId", auth.RequireRole(model.RoleAdmin), resellerHandler.SetActive)
+
+	webhookGroup := r.Group("/webhooks", auth.RequireRole(model.RoleAdmin))
+	{
+		webhookGroup.GET("", webhookHandler.GetAll)
+		webhookGroup.GET("/:id", webhookHandler.GetByID)
+		webhookGroup.POST("/:id/resend", webhookHandler.Resend)
+	}
+
+	h2hGroup := r.Group("/h2h")
+	{
+		h2hGroup.POST("/price-list", resellerHandler.PriceList)
+		h2hGroup.POST("/balance", resellerHandler.Balance)
+		h2hGroup.POST("/transaction", resellerHandler.Purchase)
+		h2hGroup.POST("/status", resellerHandler.Status)
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"database/sql"
 
 	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/middleware"
 )
 
-func SetupAllRoutes(r *gin.RouterGroup, DB *sql.DB) {
-	SubCatgeoryRoutes(r, DB)
-	CategoryRoutes(r, DB)
-	NewsRoutes(r, DB)
-	MethodRoutes(r, DB)
-	ProductRoutes(r,DB)
-	AuthRoutes(r,DB)
+func SetupAllRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
+	SubCatgeoryRoutes(r, DB, auth)
+	CategoryRoutes(r, DB, auth)
+	NewsRoutes(r, DB, auth)
+	MethodRoutes(r, DB, auth)
+	ProductRoutes(r, DB, auth)
+	WalletRoutes(r, DB, auth)
+	PriceHistoryRoutes(r, DB, auth)
 }
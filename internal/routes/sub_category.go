@@ -5,21 +5,25 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
 	"github.com/wafi04/otomaxv2/internal/repository"
 	"github.com/wafi04/otomaxv2/internal/services"
 )
 
-func SubCatgeoryRoutes(r *gin.RouterGroup, DB *sql.DB) {
+func SubCatgeoryRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
 	subCategoryRepo := repository.NewSubCategory(DB)
 	subCategoryService := services.NewSubCategoryService(subCategoryRepo)
 	subCategoryHandler := handler.NewSubCategoryHandler(subCategoryService)
 
+	admin := auth.RequireRole(model.RoleAdmin)
+
 	categoryGroup := r.Group("/subcategories")
 	{
-		categoryGroup.POST("", subCategoryHandler.CreateSubCategory)
+		categoryGroup.POST("", admin, subCategoryHandler.CreateSubCategory)
 		categoryGroup.GET("", subCategoryHandler.GetAllSubCategories)
 		categoryGroup.GET("/:id", subCategoryHandler.GetSubCategoryByID)
-		categoryGroup.PUT("/:id", subCategoryHandler.UpdateSubCategory)
-		categoryGroup.DELETE("/:id", subCategoryHandler.DeleteSubCategory)
+		categoryGroup.PUT("/:id", admin, subCategoryHandler.UpdateSubCategory)
+		categoryGroup.DELETE("/:id", admin, subCategoryHandler.DeleteSubCategory)
 	}
 }
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/config"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/integrations/provider"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/internal/worker"
+)
+
+func SupplierBalanceRoutes(r *gin.RouterGroup, cfg config.Config, DB *sql.DB, auth *middleware.AuthMiddleware) {
+	digiService := digiflazz.NewDigiflazzService(digiflazz.DigiConfig{
+		DigiKey:      cfg.Digiflazz.DigiKey,
+		DigiUsername: cfg.Digiflazz.DigiUsername,
+		CallbackURL:  cfg.Digiflazz.CallbackURL,
+	})
+
+	balanceService := services.NewSupplierBalanceService(
+		repository.NewSupplierBalanceRepository(DB),
+		provider.NewRegistry(digiService),
+		cfg.Order.SupplierBalanceBuffer,
+		cfg.Order.SupplierBalanceMaxAge,
+	)
+	balanceHandler := handler.NewSupplierBalanceHandler(balanceService)
+
+	go worker.NewSupplierBalanceMonitor(balanceService, cfg.Order.SupplierBalanceInterval).Start(context.Background())
+
+	balanceGroup := r.Group("/supplier-balances", auth.RequireRole(model.RoleAdmin))
+	{
+		balanceGroup.GET("", balanceHandler.GetAll)
+		balanceGroup.POST("/refresh", balanceHandler.Refresh)
+	}
+}
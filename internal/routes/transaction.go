@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/wafi04/otomaxv2/internal/config"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/integrations/duitku"
+	"github.com/wafi04/otomaxv2/internal/integrations/provider"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+	"github.com/wafi04/otomaxv2/internal/worker"
+)
+
+func TransactionRoutes(r *gin.RouterGroup, cfg config.Config, DB *sql.DB, redisClient *redis.Client, auth *middleware.AuthMiddleware) {
+	digiService := digiflazz.NewDigiflazzService(digiflazz.DigiConfig{
+		DigiKey:      cfg.Digiflazz.DigiKey,
+		DigiUsername: cfg.Digiflazz.DigiUsername,
+		CallbackURL:  cfg.Digiflazz.CallbackURL,
+	})
+	duitkuCfg := cfg.PaymentGateway.DuitkuConfig
+
+	walletService := services.NewWalletService(repository.NewWalletRepository(DB))
+	transactionRepo := repository.NewTransactionRepository(DB)
+	providers := provider.NewRegistry(digiService)
+	balanceService := services.NewSupplierBalanceService(
+		repository.NewSupplierBalanceRepository(DB),
+		providers,
+		cfg.Order.SupplierBalanceBuffer,
+		cfg.Order.SupplierBalanceMaxAge,
+	)
+	orderRouter := services.NewOrderRouter(transactionRepo, providers, balanceService, cfg.Order.PurchaseTimeout)
+	resellerRepo := repository.NewResellerRepository(DB)
+	webhookService := services.NewWebhookService(
+		repository.NewWebhookRepository(DB),
+		resellerRepo,
+		transactionRepo,
+		cfg.Reseller.CallbackTimeout,
+		services.WebhookRetryPolicy{
+			MaxAttempts: cfg.Reseller.WebhookMaxAttempts,
+			Backoff:     cfg.Reseller.WebhookBackoff,
+			MaxBackoff:  cfg.Reseller.WebhookMaxBackoff,
+		},
+	)
+	transactionService := services.NewTransactionService(
+		transactionRepo,
+		repository.NewMethodRepository(DB),
+		orderRouter,
+		balanceService,
+		duitku.NewDuitkuService(&cfg),
+		walletService,
+		webhookService,
+		cfg.Order.DuplicateWindow,
+		duitkuCfg.OrderCallbackURL,
+		duitkuCfg.ReturnURL,
+	)
+	transactionHandler := handler.NewTransactionHandler(transactionService, cfg.Digiflazz.WebhookSecret)
+	billService := services.NewBillService(
+		repository.NewBillRepository(DB),
+		transactionRepo,
+		orderRouter,
+		balanceService,
+		transactionService,
+		cfg.Order.BillInquiryTTL,
+	)
+	billHandler := handler.NewBillHandler(billService)
+	resellerService := services.NewResellerService(resellerRepo, transactionRepo, transactionService, walletService)
+	gatewayService := services.NewGatewayService(
+		repository.NewAuthRepository(DB),
+		repository.NewGatewayRepository(DB),
+		transactionRepo,
+		transactionService,
+		walletService,
+		services.NewAttemptLimiter(redisClient, "gateway-pin", cfg.Gateway.PINMaxAttempts, cfg.Gateway.PINLockout),
+	)
+
+	idempotency := middleware.NewIdempotencyMiddleware(repository.NewIdempotencyRepository(DB), cfg.Idempotency.KeyTTL)
+
+	transactionGroup := r.Group("/transactions")
+	{
+		transactionGroup.POST("", auth.Optional(), idempotency.Handle("transactions.create"), transactionHandler.Create)
+		transactionGroup.GET("", auth.Authenticate(), transactionHandler.GetAll)
+		transactionGroup.GET("/escalated", auth.RequireRole(model.RoleAdmin), transactionHandler.GetEscalated)
+		transactionGroup.GET("/:refId", auth.Authenticate(), transactionHandler.GetByRefID)
+		transactionGroup.POST("/callback/digiflazz", transactionHandler.DigiflazzCallback)
+		transactionGroup.POST("/callback/duitku", transactionHandler.DuitkuCallback)
+	}
+
+	billGroup := r.Group("/bills")
+	{
+		billGroup.POST("/inquiry", auth.Optional(), billHandler.Inquire)
+		billGroup.GET("/:refId", auth.Authenticate(), billHandler.GetByRefID)
+		billGroup.POST("/:refId/pay", auth.Optional(), idempotency.Handle("bills.pay"), billHandler.Pay)
+	}
+
+	resellerRoutes(r, auth, resellerService, webhookService)
+	gatewayRoutes(r, auth, gatewayService, cfg.Gateway.RelaySecret)
+
+	go worker.NewStatusPoller(transactionService, cfg.Order.StatusPollInterval, services.StatusPollPolicy{
+		After:         cfg.Order.StatusCheckAfter,
+		MaxBackoff:    cfg.Order.StatusCheckMaxBackoff,
+		EscalateAfter: cfg.Order.StatusEscalateAfter,
+	}).Start(context.Background())
+	go worker.NewWebhookDispatcher(webhookService, cfg.Reseller.WebhookRetryInterval).Start(context.Background())
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/otomaxv2/internal/handler"
+	"github.com/wafi04/otomaxv2/internal/middleware"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+func WalletRoutes(r *gin.RouterGroup, DB *sql.DB, auth *middleware.AuthMiddleware) {
+	walletRepo := repository.NewWalletRepository(DB)
+	walletService := services.NewWalletService(walletRepo)
+	walletHandler := handler.NewWalletHandler(walletService)
+
+	walletGroup := r.Group("/wallet", auth.Authenticate())
+	{
+		walletGroup.GET("/:username", walletHandler.GetBalance)
+		walletGroup.GET("/:username/ledger", walletHandler.GetLedger)
+		walletGroup.GET("/:username/audit", auth.RequireRole(model.RoleAdmin), walletHandler.Audit)
+	}
+}
@@ -1,19 +1,298 @@
 package services
 
-import "github.com/wafi04/otomaxv2/internal/repository"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/crypto"
+	"github.com/wafi04/otomaxv2/pkg/token"
+	"github.com/wafi04/otomaxv2/pkg/validator"
+)
+
+var (
+	ErrEmailNotVerified   = errors.New("google account email is not verified")
+	ErrUserInactive       = errors.New("user is not active")
+	ErrInvalidCredentials = errors.New("invalid username/email or password")
+	ErrUserExists         = errors.New("username, email or phone is already registered")
+	ErrPhoneMissing       = errors.New("user has no phone number")
+	ErrPhoneVerified      = errors.New("phone number is already verified")
+	ErrPINIncorrect       = errors.New("current PIN is incorrect")
+	ErrGoogleLinkRequired = errors.New("an account with this email already exists, sign in with your password and link Google from your settings")
+	ErrGoogleLinkConflict = errors.New("google account is linked to another user, or this user already has one linked")
+)
+
+const otpPurposePhone = "phone"
+
+var usernameSanitizer = regexp.MustCompile(`[^a-z0-9_]`)
 
 type AuthService struct {
-	repo *repository.AuthRepository
+	repo   *repository.AuthRepository
+	tokens *token.Manager
+	otp    *OTPService
 }
 
-
-func NewAuthService(repo *repository.AuthRepository) *AuthService{
+func NewAuthService(repo *repository.AuthRepository, tokens *token.Manager, otp *OTPService) *AuthService {
 	return &AuthService{
-		repo: repo,
+		repo:   repo,
+		tokens: tokens,
+		otp:    otp,
+	}
+}
+
+// Register creates a password account, signs the user in and sends the first
+// phone verification code. A failed OTP delivery does not fail registration;
+// the user can request a new code.
+func (s *AuthService) Register(ctx context.Context, req model.RegisterRequest) (*model.LoginResponse, error) {
+	req.Username = strings.TrimSpace(req.Username)
+	req.Phone = validator.NormalizePhoneNumber(req.Phone)
+
+	passwordHash, err := crypto.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.CreateUser(ctx, req, passwordHash)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrUserExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SendPhoneOTP(ctx, user); err != nil {
+		log.Printf("Failed to send phone OTP to user %d: %v", user.ID, err)
+	}
+
+	tokens, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginResponse{User: user, Tokens: tokens}, nil
+}
+
+func (s *AuthService) Login(ctx context.Context, req model.LoginRequest) (*model.LoginResponse, error) {
+	user, passwordHash, err := s.repo.GetCredentials(ctx, strings.TrimSpace(req.Identifier))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || passwordHash == "" || !crypto.VerifyPassword(req.Password, passwordHash) {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Status != model.UserStatusActive {
+		return nil, ErrUserInactive
 	}
+
+	tokens, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginResponse{User: user, Tokens: tokens}, nil
 }
 
+// SendPhoneOTP sends a verification code to the user's phone number.
+func (s *AuthService) SendPhoneOTP(ctx context.Context, user *model.UserData) error {
+	if user.Phone == nil || *user.Phone == "" {
+		return ErrPhoneMissing
+	}
+	if user.PhoneVerifiedAt != nil {
+		return ErrPhoneVerified
+	}
+	return s.otp.Send(ctx, otpPurposePhone, strconv.Itoa(user.ID), *user.Phone)
+}
 
-func (s *AuthService) GetCallback(){
-	
-}
\ No newline at end of file
+func (s *AuthService) VerifyPhone(ctx context.Context, user *model.UserData, code string) error {
+	if user.PhoneVerifiedAt != nil {
+		return ErrPhoneVerified
+	}
+	if err := s.otp.Verify(ctx, otpPurposePhone, strconv.Itoa(user.ID), code); err != nil {
+		return err
+	}
+	return s.repo.MarkPhoneVerified(ctx, user.ID)
+}
+
+// SetPIN sets the PIN the user signs text gateway commands with. Changing an
+// existing PIN requires the current one.
+func (s *AuthService) SetPIN(ctx context.Context, user *model.UserData, req model.SetPINRequest) error {
+	current, err := s.repo.GetPINHash(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if current != "" && !crypto.VerifyPassword(req.CurrentPIN, current) {
+		return ErrPINIncorrect
+	}
+
+	pinHash, err := crypto.HashPassword(req.PIN)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetPIN(ctx, user.ID, pinHash)
+}
+
+// LoginWithGoogle signs in the user linked to a Google profile, creating one
+// when its email is new. An existing account is only linked automatically when
+// it has no password or its email was already verified; anyone can register an
+// unverified email, so a password account must link Google from its settings.
+func (s *AuthService) LoginWithGoogle(ctx context.Context, profile model.GoogleCallback) (*model.LoginResponse, error) {
+	if profile.Email == "" || !profile.VerifiedEmail {
+		return nil, ErrEmailNotVerified
+	}
+
+	user, err := s.repo.GetByGoogleID(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		user, err = s.repo.UpdateGoogleProfile(ctx, user.ID, profile)
+	} else {
+		user, err = s.signUpWithGoogle(ctx, profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != model.UserStatusActive {
+		return nil, ErrUserInactive
+	}
+
+	tokens, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginResponse{User: user, Tokens: tokens}, nil
+}
+
+// signUpWithGoogle links a Google profile that is not linked yet to the
+// account owning its email, or creates an account when there is none.
+func (s *AuthService) signUpWithGoogle(ctx context.Context, profile model.GoogleCallback) (*model.UserData, error) {
+	account, err := s.repo.GetAccountLink(ctx, profile.Email)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		username, err := s.availableUsername(ctx, profile.Email)
+		if err != nil {
+			return nil, err
+		}
+		user, err := s.repo.CreateGoogleUser(ctx, profile, username)
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrGoogleLinkRequired
+		}
+		return user, err
+	}
+
+	if account.GoogleID != "" || (account.HasPassword && !account.EmailVerified) {
+		return nil, ErrGoogleLinkRequired
+	}
+	user, err := s.repo.LinkGoogle(ctx, account.User.ID, profile)
+	if errors.Is(err, repository.ErrDuplicate) || (err == nil && user == nil) {
+		return nil, ErrGoogleLinkRequired
+	}
+	return user, err
+}
+
+// LinkGoogle attaches a Google profile to a signed-in user, so they can log in
+// with Google from then on.
+func (s *AuthService) LinkGoogle(ctx context.Context, userID int, profile model.GoogleCallback) (*model.UserData, error) {
+	if profile.Email == "" || !profile.VerifiedEmail {
+		return nil, ErrEmailNotVerified
+	}
+
+	user, err := s.repo.LinkGoogle(ctx, userID, profile)
+	if errors.Is(err, repository.ErrDuplicate) || (err == nil && user == nil) {
+		return nil, ErrGoogleLinkConflict
+	}
+	return user, err
+}
+
+// Refresh spends a refresh token and issues a new token pair. Each refresh
+// token works once; replaying it fails.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*model.AuthTokens, error) {
+	claims, err := s.tokens.Parse(refreshToken, token.TypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, ok, err := s.repo.RevokeRefreshToken(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, token.ErrInvalidToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Status != model.UserStatusActive {
+		return nil, ErrUserInactive
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// Logout revokes the session's refresh token. Access tokens stay valid until
+// they expire, so their lifetime should be kept short.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.tokens.Parse(refreshToken, token.TypeRefresh)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.repo.RevokeRefreshToken(ctx, claims.ID)
+	return err
+}
+
+func (s *AuthService) issueTokens(ctx context.Context, user *model.UserData) (*model.AuthTokens, error) {
+	role := string(model.ParseUserRole(string(user.Role)))
+
+	accessToken, err := s.tokens.GenerateAccess(user.ID, user.Username, role)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID := crypto.GenerateRandomString(32)
+	if err := s.repo.CreateRefreshToken(ctx, tokenID, user.ID, time.Now().Add(s.tokens.RefreshTTL())); err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.tokens.GenerateRefresh(user.ID, user.Username, role, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.tokens.AccessTTL().Seconds()),
+	}, nil
+}
+
+// availableUsername derives a username from the email's local part, adding a
+// random suffix when it is taken.
+func (s *AuthService) availableUsername(ctx context.Context, email string) (string, error) {
+	base := strings.ToLower(strings.SplitN(email, "@", 2)[0])
+	base = usernameSanitizer.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		exists, err := s.repo.UsernameExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s_%s", base, strings.ToLower(crypto.GenerateRandomString(4)))
+	}
+	return candidate, nil
+}
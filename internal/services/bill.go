@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/integrations/provider"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/utils"
+)
+
+var (
+	ErrNotPostpaid       = errors.New("product is not a bill payment")
+	ErrBillNotFound      = errors.New("bill not found")
+	ErrBillExpired       = errors.New("bill inquiry has expired, inquire again")
+	ErrBillPaid          = errors.New("bill has already been paid")
+	ErrBillInquiryFailed = errors.New("bill inquiry failed")
+	ErrBillBelowCost     = errors.New("bill total is below the provider price")
+)
+
+// BillService handles postpaid bills: an inquiry locks the bill total for a
+// short TTL, and paying it checks out a transaction for exactly that total.
+type BillService struct {
+	bills        *repository.BillRepository
+	orders       *repository.TransactionRepository
+	router       *OrderRouter
+	balances     *SupplierBalanceService
+	transactions *TransactionService
+	ttl          time.Duration
+}
+
+func NewBillService(
+	bills *repository.BillRepository,
+	orders *repository.TransactionRepository,
+	router *OrderRouter,
+	balances *SupplierBalanceService,
+	transactions *TransactionService,
+	ttl time.Duration,
+) *BillService {
+	return &BillService{
+		bills:        bills,
+		orders:       orders,
+		router:       router,
+		balances:     balances,
+		transactions: transactions,
+		ttl:          ttl,
+	}
+}
+
+// Inquire fetches the customer's open bill from the provider and stores it.
+// The admin fee is what the provider charges for this bill on top of its
+// amount, which grows with the number of periods, plus our margin: the
+// difference between the role price and the SKU's listed fee.
+func (s *BillService) Inquire(ctx context.Context, req model.CreateBillInquiry) (*model.Bill, error) {
+	product, err := s.orders.GetProductForOrder(ctx, req.ProductID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductUnavailable
+	}
+	if !product.Postpaid {
+		return nil, ErrNotPostpaid
+	}
+	if product.InCutOff {
+		return nil, ErrProductCutOff
+	}
+
+	prefix := "INQ"
+	refID := utils.GenerateUniqeID(&prefix)
+	customerNo := strings.TrimSpace(req.CustomerNo)
+	inquiry, err := s.router.Inquiry(ctx, product.ProviderSlug, provider.PurchaseRequest{
+		SKU:        product.ProviderCode,
+		CustomerNo: customerNo,
+		RefID:      refID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if inquiry.Status != model.TransactionStatusSuccess {
+		return nil, fmt.Errorf("%w: %s", ErrBillInquiryFailed, inquiry.Message)
+	}
+
+	adminFee := inquiry.Price - inquiry.Amount + product.Price - product.CostPrice
+	total := inquiry.Amount + adminFee
+	if total < inquiry.Price {
+		return nil, fmt.Errorf("%w: total %d, provider price %d", ErrBillBelowCost, total, inquiry.Price)
+	}
+
+	bill := &model.Bill{
+		RefID:             refID,
+		Username:          req.Username,
+		ProductID:         product.ProductID,
+		ProductName:       product.ProductName,
+		ProviderProductID: product.ProviderProductID,
+		ProviderCode:      product.ProviderCode,
+		ProviderSlug:      product.ProviderSlug,
+		CustomerNo:        customerNo,
+		CustomerName:      inquiry.CustomerName,
+		Period:            inquiry.Period,
+		Amount:            inquiry.Amount,
+		AdminFee:          adminFee,
+		CostPrice:         inquiry.Price,
+		Total:             total,
+		Status:            model.BillStatusOpen,
+		ExpiresAt:         time.Now().Add(s.ttl),
+	}
+	if err := s.bills.Create(ctx, bill); err != nil {
+		return nil, err
+	}
+	return bill, nil
+}
+
+// GetForUser returns a bill to the member who inquired it, or to an admin.
+func (s *BillService) GetForUser(ctx context.Context, refID, username string, admin bool) (*model.Bill, error) {
+	bill, err := s.bills.GetByRefID(ctx, refID)
+	if err != nil {
+		return nil, err
+	}
+	if bill == nil || (!admin && bill.Username != username) {
+		return nil, ErrBillNotFound
+	}
+	return bill, nil
+}
+
+// GetByRefID returns a bill for payment by the member who inquired it. Bills
+// inquired without logging in can be paid by anyone holding the ref.
+func (s *BillService) GetByRefID(ctx context.Context, refID, username string) (*model.Bill, error) {
+	bill, err := s.bills.GetByRefID(ctx, refID)
+	if err != nil {
+		return nil, err
+	}
+	if bill == nil || (bill.Username != "" && bill.Username != username) {
+		return nil, ErrBillNotFound
+	}
+	return bill, nil
+}
+
+// Pay checks out a transaction for the locked bill total. The bill is claimed
+// in the same SQL transaction that stores the order, so it is paid only once.
+func (s *BillService) Pay(ctx context.Context, refID string, req model.PayBill) (*model.Transaction, error) {
+	bill, err := s.GetByRefID(ctx, refID, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if bill.Status != model.BillStatusOpen {
+		return nil, ErrBillPaid
+	}
+	if !time.Now().Before(bill.ExpiresAt) {
+		return nil, ErrBillExpired
+	}
+	if err := s.balances.EnsureSufficient(ctx, bill.ProviderSlug, bill.CostPrice); err != nil {
+		return nil, err
+	}
+
+	prefix := "TRX"
+	trx := &model.Transaction{
+		RefID:             utils.GenerateUniqeID(&prefix),
+		Username:          req.Username,
+		ProductID:         bill.ProductID,
+		ProductName:       bill.ProductName,
+		ProviderProductID: bill.ProviderProductID,
+		ProviderCode:      bill.ProviderCode,
+		ProviderSlug:      bill.ProviderSlug,
+		CustomerNo:        bill.CustomerNo,
+		Price:             bill.Total,
+		CostPrice:         bill.CostPrice,
+		Total:             bill.Total,
+		PaymentMethod:     strings.ToUpper(strings.TrimSpace(req.Method)),
+		PaymentStatus:     model.PaymentStatusUnpaid,
+		Status:            model.TransactionStatusPending,
+		BillRefID:         &bill.RefID,
+	}
+
+	return s.transactions.Checkout(ctx, trx, func(exec repository.DBTX) error {
+		claimed, err := s.bills.Claim(ctx, exec, bill.RefID, trx.RefID)
+		if err != nil {
+			return err
+		}
+		if !claimed && !time.Now().Before(bill.ExpiresAt) {
+			return ErrBillExpired
+		}
+		if !claimed {
+			return ErrBillPaid
+		}
+		return nil
+	})
+}
@@ -25,9 +25,10 @@ func (s *CategoryService) GetCategoryByID(ctx context.Context, id int) (*model.C
 	return s.categoryRepo.GetByID(ctx, id)
 }
 
-func (s *CategoryService) GetCategoryByCode(ctx context.Context, code string, subCategoryId *int) (*model.CategoryCodeResponse, error) {
+func (s *CategoryService) GetCategoryByCode(ctx context.Context, code string, subCategoryId *int, role model.UserRole) (*model.CategoryCodeResponse, error) {
 	return s.categoryRepo.GetByCodeWithFilter(ctx, code, &repository.CategoryFilter{
 		SubCategoryID: subCategoryId,
+		Role:          role,
 	})
 }
 
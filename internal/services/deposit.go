@@ -2,22 +2,76 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
 
 	"github.com/wafi04/otomaxv2/internal/integrations/duitku"
 	"github.com/wafi04/otomaxv2/internal/model"
 	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/utils"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrDepositNotFound  = errors.New("deposit not found")
+	ErrAmountMismatch   = errors.New("amount does not match deposit")
 )
 
 type DepositService struct {
-	repo   *repository.DepositRepository
-	duitku *duitku.DuitkuService
+	repo        *repository.DepositRepository
+	duitku      *duitku.DuitkuService
+	wallet      *WalletService
+	callbackUrl string
+	returnUrl   string
 }
 
-func NewDuitkuService(repo *repository.DepositRepository, duitku *duitku.DuitkuService) *DepositService {
+func NewDuitkuService(repo *repository.DepositRepository, duitku *duitku.DuitkuService, wallet *WalletService, callbackUrl, returnUrl string) *DepositService {
 	return &DepositService{
-		repo:   repo,
-		duitku: duitku,
+		repo:        repo,
+		duitku:      duitku,
+		wallet:      wallet,
+		callbackUrl: callbackUrl,
+		returnUrl:   returnUrl,
+	}
+}
+
+// CreatePayment opens a Duitku invoice and records the deposit as PENDING.
+func (ds *DepositService) CreatePayment(c context.Context, req model.RequestFormClient, username string) (*duitku.DuitkuCreateTransactionResponse, error) {
+	depStr := "DEP"
+	depositID := utils.GenerateUniqeID(&depStr)
+
+	duitkuCall, err := ds.duitku.CreateTransaction(c, &duitku.DuitkuCreateTransactionParams{
+		PaymentAmount:   req.Amount,
+		MerchantOrderId: depositID,
+		ProductDetails:  "Deposit",
+		PaymentCode:     req.Method,
+		CallbackUrl:     &ds.callbackUrl,
+		ReturnUrl:       &ds.returnUrl,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if duitkuCall == nil || duitkuCall.Reference == "" {
+		return nil, fmt.Errorf("duitku did not return a payment reference")
 	}
+
+	_, err = ds.repo.Create(c, model.CreateDeposit{
+		DepositID:         depositID,
+		Amount:            req.Amount,
+		Method:            req.Method,
+		Username:          username,
+		DestinationNumber: "",
+		PaymentReferee:    &duitkuCall.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return duitkuCall, nil
 }
 
 func (ds *DepositService) Create(c context.Context, req model.CreateDeposit) (bool, error) {
@@ -31,3 +85,116 @@ func (ds *DepositService) GetAll(c context.Context, req model.FilterDeposit) ([]
 func (ds *DepositService) GetByID(c context.Context, id int) (*model.DepositData, error) {
 	return ds.repo.GetByID(c, id)
 }
+
+// HandleDuitkuCallback verifies a Duitku payment callback and settles the deposit.
+func (ds *DepositService) HandleDuitkuCallback(c context.Context, params *duitku.DuitkuCallbackParams) error {
+	if !ds.duitku.VerifyCallbackSignature(params) {
+		return ErrInvalidSignature
+	}
+
+	dep, err := ds.repo.GetByDepositID(c, params.MerchantOrderId)
+	if err != nil {
+		return err
+	}
+	if dep == nil {
+		return ErrDepositNotFound
+	}
+
+	if amount, err := strconv.Atoi(params.Amount); err != nil || amount != dep.Amount {
+		return ErrAmountMismatch
+	}
+
+	status := model.DepositStatusFailed
+	if params.ResultCode == duitku.CallbackResultSuccess {
+		status = model.DepositStatusPaid
+	}
+
+	return ds.settle(c, dep, status, &params.Reference)
+}
+
+// ReconcilePending asks Duitku about deposits whose callback never arrived and
+// settles the ones that are no longer pending. A deposit that stays pending is
+// checked again after a delay that doubles up to maxBackoff. It returns how
+// many were settled.
+func (ds *DepositService) ReconcilePending(c context.Context, olderThan, maxBackoff time.Duration) (int, error) {
+	deposits, err := ds.repo.GetPendingBefore(c, time.Now().Add(-olderThan), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	policy := StatusPollPolicy{After: olderThan, MaxBackoff: maxBackoff}
+	settled := 0
+	for i := range deposits {
+		dep := &deposits[i]
+
+		if ds.reconcile(c, dep) {
+			settled++
+			continue
+		}
+		if err := ds.repo.ScheduleCheck(c, dep.DepositID, time.Now().Add(statusCheckBackoff(policy, dep.StatusChecks))); err != nil {
+			log.Printf("Failed to schedule check for deposit %s: %v", dep.DepositID, err)
+		}
+	}
+
+	return settled, nil
+}
+
+// reconcile settles one deposit from Duitku's status and reports whether it did.
+// A paid amount that differs from the deposit is never credited.
+func (ds *DepositService) reconcile(c context.Context, dep *model.DepositData) bool {
+	result, err := ds.duitku.CheckTransaction(c, dep.DepositID)
+	if err != nil {
+		log.Printf("Duitku CheckTransaction failed for %s: %v", dep.DepositID, err)
+		return false
+	}
+
+	var status string
+	switch result.Data.StatusCode {
+	case duitku.TransactionStatusSuccess:
+		status = model.DepositStatusPaid
+		if amount, err := strconv.Atoi(result.Data.Amount); err != nil || amount != dep.Amount {
+			log.Printf("ESCALATION: deposit %s paid %q at Duitku but was created for %d",
+				dep.DepositID, result.Data.Amount, dep.Amount)
+			return false
+		}
+	case duitku.TransactionStatusCanceled:
+		status = model.DepositStatusFailed
+	default:
+		return false
+	}
+
+	reference := result.Data.Reference
+	if err := ds.settle(c, dep, status, &reference); err != nil {
+		log.Printf("Failed to settle deposit %s: %v", dep.DepositID, err)
+		return false
+	}
+	return true
+}
+
+// settle marks the deposit and, when paid, credits the wallet in the same SQL
+// transaction so a deposit is never PAID without its ledger entry.
+func (ds *DepositService) settle(c context.Context, dep *model.DepositData, status string, reference *string) error {
+	return ds.wallet.RunInTx(c, func(tx *sql.Tx) error {
+		updated, err := ds.repo.UpdateStatus(c, tx, dep.DepositID, status, reference)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			log.Printf("Deposit %s already settled, ignoring %s", dep.DepositID, status)
+			return nil
+		}
+		if status != model.DepositStatusPaid {
+			return nil
+		}
+
+		_, err = ds.wallet.ApplyTx(c, tx, model.WalletMutation{
+			Username:      dep.Username,
+			EntryType:     model.LedgerEntryCredit,
+			Amount:        int64(dep.Amount),
+			ReferenceType: model.LedgerReferenceDeposit,
+			ReferenceID:   dep.DepositID,
+			Description:   fmt.Sprintf("Deposit via %s", dep.Method),
+		})
+		return err
+	})
+}
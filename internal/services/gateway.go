@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/crypto"
+	"github.com/wafi04/otomaxv2/pkg/validator"
+)
+
+var (
+	ErrKeywordInvalid  = errors.New("keyword must be 1-30 letters, digits or dashes")
+	ErrKeywordReserved = errors.New("keyword is a reserved command word")
+	ErrKeywordTaken    = errors.New("keyword is already used by another product")
+	ErrProductNotFound = errors.New("product not found")
+)
+
+const gatewayPriceListLimit = 20
+
+// gatewayReplies are the text replies, in the terse style Otomax users expect.
+var gatewayReplies = template.Must(template.New("replies").Funcs(template.FuncMap{
+	"rupiah": formatRupiah,
+}).Parse(`
+{{- define "order"}}{{if .Success}}SUKSES{{else if .Failed}}GAGAL{{else}}PROSES{{end}} {{.Trx.ProductName}} ke {{.Trx.CustomerNo}}
+{{- if .SN}} SN:{{.SN}}{{end}}{{if and .Failed .Message}} Ket:{{.Message}}{{end}} Harga:{{rupiah .Trx.Total}}
+{{- if .Balance}} Saldo:{{rupiah .Balance}}{{end}} Ref:{{.Trx.RefID}}{{end}}
+{{- define "balance"}}Saldo {{.Username}}: {{rupiah .Balance}}{{end}}
+{{- define "price"}}HARGA {{.Query}}:{{range .Products}}
+{{.Keyword}} {{rupiah .Price}}{{end}}{{end}}
+`))
+
+type gatewayOrderReply struct {
+	Trx     *model.Transaction
+	Success bool
+	Failed  bool
+	SN      string
+	Message string
+	Balance int64
+}
+
+// GatewayService runs Otomax-style text commands. Senders are identified by
+// their verified phone number and prove each command with their PIN. A phone
+// that sends too many wrong PINs is locked out for a while.
+type GatewayService struct {
+	users        *repository.AuthRepository
+	keywords     *repository.GatewayRepository
+	orders       *repository.TransactionRepository
+	transactions *TransactionService
+	wallet       *WalletService
+	pinAttempts  *AttemptLimiter
+}
+
+func NewGatewayService(
+	users *repository.AuthRepository,
+	keywords *repository.GatewayRepository,
+	orders *repository.TransactionRepository,
+	transactions *TransactionService,
+	wallet *WalletService,
+	pinAttempts *AttemptLimiter,
+) *GatewayService {
+	return &GatewayService{
+		users:        users,
+		keywords:     keywords,
+		orders:       orders,
+		transactions: transactions,
+		wallet:       wallet,
+		pinAttempts:  pinAttempts,
+	}
+}
+
+// Handle runs one message and returns the reply for the sender. Failures are
+// reported in the reply; internal errors are logged.
+func (s *GatewayService) Handle(ctx context.Context, msg model.GatewayMessage) string {
+	cmd, err := ParseCommand(msg.Message)
+	if err != nil {
+		return "Format salah. Contoh: KODE.TUJUAN.PIN, SALDO.PIN, HARGA.KODE.PIN, STATUS.TUJUAN.PIN"
+	}
+
+	phone := validator.NormalizePhoneNumber(msg.Sender)
+	locked, err := s.pinAttempts.Blocked(ctx, phone)
+	if err != nil {
+		log.Printf("Gateway PIN limiter %s failed: %v", phone, err)
+		return "Sistem sedang gangguan, silakan coba lagi"
+	}
+	if locked {
+		return "PIN terkunci karena terlalu banyak percobaan salah, silakan coba lagi nanti"
+	}
+
+	user, pinHash, err := s.users.GetByPhone(ctx, phone)
+	if err != nil {
+		log.Printf("Gateway sender lookup %s failed: %v", msg.Sender, err)
+		return "Sistem sedang gangguan, silakan coba lagi"
+	}
+	if user == nil {
+		return "Nomor tidak terdaftar"
+	}
+	if pinHash == "" || !crypto.VerifyPassword(cmd.PIN, pinHash) {
+		locked, err := s.pinAttempts.Hit(ctx, phone)
+		if err != nil {
+			log.Printf("Gateway PIN limiter %s failed: %v", phone, err)
+		}
+		if locked {
+			return "PIN salah. PIN terkunci karena terlalu banyak percobaan salah, silakan coba lagi nanti"
+		}
+		return "PIN salah"
+	}
+	if err := s.pinAttempts.Reset(ctx, phone); err != nil {
+		log.Printf("Gateway PIN limiter %s failed: %v", phone, err)
+	}
+	if user.Status != model.UserStatusActive {
+		return "Akun tidak aktif"
+	}
+
+	var reply string
+	switch cmd.Kind {
+	case CommandBalance:
+		reply, err = s.balance(ctx, user)
+	case CommandPrice:
+		reply, err = s.prices(ctx, user, cmd.Query)
+	case CommandStatus:
+		reply, err = s.status(ctx, user, cmd.Destination)
+	default:
+		reply, err = s.purchase(ctx, user, cmd)
+	}
+	if err != nil {
+		return gatewayErrorReply(user, cmd, err)
+	}
+	return reply
+}
+
+func (s *GatewayService) balance(ctx context.Context, user *model.UserData) (string, error) {
+	balance, err := s.wallet.GetBalance(ctx, user.Username)
+	if err != nil {
+		return "", err
+	}
+	return renderReply("balance", balance)
+}
+
+func (s *GatewayService) prices(ctx context.Context, user *model.UserData, prefix string) (string, error) {
+	products, err := s.keywords.GetByKeywordPrefix(ctx, prefix, user.Role, gatewayPriceListLimit)
+	if err != nil {
+		return "", err
+	}
+	if len(products) == 0 {
+		return "Produk " + prefix + " tidak ditemukan", nil
+	}
+	return renderReply("price", map[string]interface{}{"Query": prefix, "Products": products})
+}
+
+// status looks an order up by our ref id, or else by the destination number.
+func (s *GatewayService) status(ctx context.Context, user *model.UserData, destination string) (string, error) {
+	trx, err := s.orders.GetByRefID(ctx, destination)
+	if err != nil {
+		return "", err
+	}
+	if trx == nil || trx.Username != user.Username {
+		trx, err = s.orders.GetLatestByCustomerNo(ctx, user.Username, destination)
+		if err != nil {
+			return "", err
+		}
+	}
+	if trx == nil {
+		return "", ErrTransactionNotFound
+	}
+	return renderReply("order", newGatewayOrderReply(trx, 0))
+}
+
+func (s *GatewayService) purchase(ctx context.Context, user *model.UserData, cmd *Command) (string, error) {
+	product, err := s.keywords.GetByKeyword(ctx, cmd.Keyword, user.Role)
+	if err != nil {
+		return "", err
+	}
+	if product == nil || !product.Available {
+		return "", ErrProductUnavailable
+	}
+
+	req := model.CreateTransaction{
+		ProductID:  product.ProductID,
+		CustomerNo: cmd.Destination,
+		Method:     model.PaymentMethodSaldo,
+		Username:   user.Username,
+		Role:       user.Role,
+		Repeat:     cmd.Repeat,
+	}
+	if cmd.ZoneID != "" {
+		req.ZoneID = &cmd.ZoneID
+	}
+	trx, err := s.transactions.Create(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	var balance int64
+	if wallet, err := s.wallet.GetBalance(ctx, user.Username); err == nil {
+		balance = wallet.Balance
+	}
+	return renderReply("order", newGatewayOrderReply(trx, balance))
+}
+
+// SetKeyword assigns the text command keyword of a product. An empty keyword
+// falls back to the product's source SKU code.
+func (s *GatewayService) SetKeyword(ctx context.Context, productID int, keyword string) error {
+	keyword = strings.ToUpper(strings.TrimSpace(keyword))
+	if keyword != "" {
+		if _, reserved := commandWords[keyword]; reserved {
+			return ErrKeywordReserved
+		}
+		if len(keyword) > 30 || strings.Trim(keyword, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-") != "" {
+			return ErrKeywordInvalid
+		}
+	}
+
+	updated, err := s.keywords.SetKeyword(ctx, productID, keyword)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrKeywordTaken
+	}
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+func newGatewayOrderReply(trx *model.Transaction, balance int64) gatewayOrderReply {
+	reply := gatewayOrderReply{
+		Trx:     trx,
+		Success: trx.Status == model.TransactionStatusSuccess,
+		Failed:  trx.Status == model.TransactionStatusFailed,
+		Balance: balance,
+	}
+	if trx.SN != nil {
+		reply.SN = *trx.SN
+	}
+	if trx.Message != nil {
+		reply.Message = *trx.Message
+	}
+	return reply
+}
+
+func gatewayErrorReply(user *model.UserData, cmd *Command, err error) string {
+	var duplicate *DuplicateOrderError
+	switch {
+	case errors.As(err, &duplicate):
+		target := cmd.Destination
+		if cmd.ZoneID != "" {
+			target += "." + cmd.ZoneID
+		}
+		return fmt.Sprintf("Transaksi %s ke %s sudah ada. Untuk mengulang kirim %s.%s.PIN#%d",
+			cmd.Keyword, target, cmd.Keyword, target, duplicate.Repeat)
+	case errors.Is(err, ErrProductUnavailable), errors.Is(err, ErrPostpaidProduct):
+		return "Produk " + cmd.Keyword + " tidak tersedia"
+	case errors.Is(err, ErrProductCutOff), errors.Is(err, ErrSupplierBalanceLow):
+		return "Produk " + cmd.Keyword + " sedang gangguan, silakan coba lagi nanti"
+	case errors.Is(err, ErrInsufficientBalance):
+		return "Saldo tidak cukup"
+	case errors.Is(err, ErrTransactionNotFound):
+		return "Transaksi " + cmd.Destination + " tidak ditemukan"
+	default:
+		log.Printf("Gateway command %s from %s failed: %v", cmd.Kind, user.Username, err)
+		return "Transaksi gagal diproses, silakan coba lagi"
+	}
+}
+
+func renderReply(name string, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := gatewayReplies.ExecuteTemplate(&b, name, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// formatRupiah renders 10000 as Rp10.000.
+func formatRupiah(amount interface{}) string {
+	var n int64
+	switch v := amount.(type) {
+	case int:
+		n = int64(v)
+	case int64:
+		n = v
+	}
+
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	digits := strconv.FormatInt(n, 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + "Rp" + b.String()
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrCommandFormat = errors.New("unrecognised command format")
+
+const (
+	CommandPurchase = "purchase"
+	CommandBalance  = "balance"
+	CommandPrice    = "price"
+	CommandStatus   = "status"
+)
+
+// commandWords are the reserved first words of non-purchase commands; any
+// other first word is a product keyword.
+var commandWords = map[string]string{
+	"SALDO":     CommandBalance,
+	"S":         CommandBalance,
+	"HARGA":     CommandPrice,
+	"CH":        CommandPrice,
+	"CEK HARGA": CommandPrice,
+	"CEKHARGA":  CommandPrice,
+	"STATUS":    CommandStatus,
+	"CEK":       CommandStatus,
+}
+
+// Command is a parsed text command. The PIN is always the last part:
+//
+//	KEYWORD.DEST.PIN  KEYWORD.DEST.ZONE.PIN  purchase
+//	SALDO.PIN                                balance
+//	HARGA.PREFIX.PIN                         prices of keywords starting with PREFIX
+//	STATUS.DEST.PIN                          latest order for DEST, or by ref id
+//
+// A purchase may end in a repeat counter, e.g. KEYWORD.DEST.PIN#2, to buy the
+// same product for the same destination again within the duplicate window.
+type Command struct {
+	Kind        string
+	Keyword     string
+	Destination string
+	ZoneID      string
+	Query       string
+	PIN         string
+	Repeat      int
+}
+
+func ParseCommand(message string) (*Command, error) {
+	message = strings.TrimSpace(message)
+	repeat := 0
+	if i := strings.LastIndex(message, "#"); i >= 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(message[i+1:]))
+		if err != nil || n < 1 {
+			return nil, ErrCommandFormat
+		}
+		message, repeat = strings.TrimSpace(message[:i]), n
+	}
+
+	parts := strings.Split(message, ".")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+		if parts[i] == "" {
+			return nil, ErrCommandFormat
+		}
+	}
+	if len(parts) < 2 {
+		return nil, ErrCommandFormat
+	}
+
+	first := strings.ToUpper(strings.Join(strings.Fields(parts[0]), " "))
+	cmd := &Command{PIN: parts[len(parts)-1]}
+	args := parts[1 : len(parts)-1]
+
+	switch kind := commandWords[first]; kind {
+	case CommandBalance:
+		if len(args) != 0 {
+			return nil, ErrCommandFormat
+		}
+		cmd.Kind = kind
+	case CommandPrice:
+		if len(args) != 1 {
+			return nil, ErrCommandFormat
+		}
+		cmd.Kind = kind
+		cmd.Query = strings.ToUpper(args[0])
+	case CommandStatus:
+		if len(args) != 1 {
+			return nil, ErrCommandFormat
+		}
+		cmd.Kind = kind
+		cmd.Destination = args[0]
+	default:
+		if len(args) < 1 || len(args) > 2 || strings.Contains(first, " ") {
+			return nil, ErrCommandFormat
+		}
+		cmd.Kind = CommandPurchase
+		cmd.Keyword = first
+		cmd.Destination = args[0]
+		if len(args) == 2 {
+			cmd.ZoneID = args[1]
+		}
+		cmd.Repeat = repeat
+	}
+	if repeat > 0 && cmd.Kind != CommandPurchase {
+		return nil, ErrCommandFormat
+	}
+	return cmd, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrLimiterUnavailable = errors.New("rate limiter is unavailable")
+
+// AttemptLimiter counts attempts per subject in Redis, like the OTP attempt
+// counter. A subject is blocked once it reaches max attempts and stays blocked
+// for window after its last counted attempt.
+type AttemptLimiter struct {
+	redis  *redis.Client
+	prefix string
+	max    int
+	window time.Duration
+}
+
+func NewAttemptLimiter(client *redis.Client, prefix string, max int, window time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		redis:  client,
+		prefix: prefix,
+		max:    max,
+		window: window,
+	}
+}
+
+func (l *AttemptLimiter) key(subject string) string {
+	return fmt.Sprintf("limit:%s:%s", l.prefix, subject)
+}
+
+// Blocked reports whether subject has used up its attempts.
+func (l *AttemptLimiter) Blocked(ctx context.Context, subject string) (bool, error) {
+	if l.redis == nil {
+		return false, ErrLimiterUnavailable
+	}
+	count, err := l.redis.Get(ctx, l.key(subject)).Int()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return count >= l.max, nil
+}
+
+// Hit counts one attempt and reports whether subject is now blocked.
+func (l *AttemptLimiter) Hit(ctx context.Context, subject string) (bool, error) {
+	if l.redis == nil {
+		return false, ErrLimiterUnavailable
+	}
+	key := l.key(subject)
+	count, err := l.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	if count == 1 || count >= int64(l.max) {
+		if err := l.redis.Expire(ctx, key, l.window).Err(); err != nil {
+			return false, err
+		}
+	}
+	return count >= int64(l.max), nil
+}
+
+// Reset clears the attempts counted for subject.
+func (l *AttemptLimiter) Reset(ctx context.Context, subject string) error {
+	if l.redis == nil {
+		return ErrLimiterUnavailable
+	}
+	return l.redis.Del(ctx, l.key(subject)).Err()
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/wafi04/otomaxv2/internal/integrations/nickname"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+)
+
+var (
+	ErrCategoryNotFound     = errors.New("category not found")
+	ErrNicknameNotSupported = errors.New("nickname check is not available for this game")
+	ErrZoneIDRequired       = errors.New("zone ID is required for this game")
+	ErrNicknameNotFound     = errors.New("no account found for this ID")
+)
+
+// nicknameMiss is cached for IDs the checker reported as unknown.
+const nicknameMiss = "-"
+
+// NicknameService looks up in-game nicknames through a nickname.Checker and
+// caches the answers in Redis, misses for a shorter time than hits.
+type NicknameService struct {
+	categories *repository.CategoryRepository
+	checker    nickname.Checker
+	redis      *redis.Client
+	cacheTTL   time.Duration
+	missTTL    time.Duration
+}
+
+func NewNicknameService(
+	categories *repository.CategoryRepository,
+	checker nickname.Checker,
+	client *redis.Client,
+	cacheTTL time.Duration,
+	missTTL time.Duration,
+) *NicknameService {
+	return &NicknameService{
+		categories: categories,
+		checker:    checker,
+		redis:      client,
+		cacheTTL:   cacheTTL,
+		missTTL:    missTTL,
+	}
+}
+
+func nicknameKey(code, userID, zoneID string) string {
+	return fmt.Sprintf("nickname:%s:%s:%s", strings.ToLower(code), userID, zoneID)
+}
+
+// Check returns the nickname of the account userID/zoneID in the game of the
+// category with the given code.
+func (s *NicknameService) Check(ctx context.Context, code string, input model.CheckNicknameRequest) (*model.NicknameResult, error) {
+	category, err := s.categories.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+	if !nicknameCheckEnabled(category.IsCheckNickname) {
+		return nil, ErrNicknameNotSupported
+	}
+
+	userID := strings.TrimSpace(input.UserID)
+	zoneID := strings.TrimSpace(input.ZoneID)
+	if category.Placeholder2 != nil && *category.Placeholder2 != "" && zoneID == "" {
+		return nil, ErrZoneIDRequired
+	}
+
+	name, err := s.lookup(ctx, nickname.Request{
+		Code:   category.Code,
+		Brand:  category.Brand,
+		UserID: userID,
+		ZoneID: zoneID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NicknameResult{
+		CategoryCode: category.Code,
+		UserID:       userID,
+		ZoneID:       zoneID,
+		Nickname:     name,
+	}, nil
+}
+
+func (s *NicknameService) lookup(ctx context.Context, req nickname.Request) (string, error) {
+	key := nicknameKey(req.Code, req.UserID, req.ZoneID)
+	if s.redis != nil {
+		cached, err := s.redis.Get(ctx, key).Result()
+		switch {
+		case err == nil && cached == nicknameMiss:
+			return "", ErrNicknameNotFound
+		case err == nil:
+			return cached, nil
+		case err != redis.Nil:
+			log.Printf("Nickname cache read failed: %v", err)
+		}
+	}
+
+	name, err := s.checker.Check(ctx, req)
+	switch {
+	case errors.Is(err, nickname.ErrNotSupported):
+		return "", ErrNicknameNotSupported
+	case errors.Is(err, nickname.ErrAccountNotFound):
+		s.cache(ctx, key, nicknameMiss, s.missTTL)
+		return "", ErrNicknameNotFound
+	case err != nil:
+		return "", err
+	}
+
+	s.cache(ctx, key, name, s.cacheTTL)
+	return name, nil
+}
+
+func (s *NicknameService) cache(ctx context.Context, key, value string, ttl time.Duration) {
+	if s.redis == nil || ttl <= 0 {
+		return
+	}
+	if err := s.redis.Set(ctx, key, value, ttl).Err(); err != nil {
+		log.Printf("Nickname cache write failed: %v", err)
+	}
+}
+
+// nicknameCheckEnabled reads categories.is_check_nickname, which admins fill
+// in as free text.
+func nicknameCheckEnabled(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "active", "true", "yes", "1":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/integrations/provider"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+)
+
+// OrderRouter picks the provider SKU that fulfils an order. Candidates are
+// tried cheapest first; a candidate that fails is recorded as a failed attempt
+// and the next one is tried. A candidate that does not answer keeps the order.
+type OrderRouter struct {
+	repo      *repository.TransactionRepository
+	providers provider.Registry
+	balances  *SupplierBalanceService
+	timeout   time.Duration
+}
+
+func NewOrderRouter(repo *repository.TransactionRepository, providers provider.Registry, balances *SupplierBalanceService, timeout time.Duration) *OrderRouter {
+	return &OrderRouter{
+		repo:      repo,
+		providers: providers,
+		balances:  balances,
+		timeout:   timeout,
+	}
+}
+
+// Route sends a Pending order to its next untried provider SKU. The result is
+// Pending or Sukses once a provider accepts the order or leaves it unanswered,
+// and Gagal once every candidate has failed. A nil result means the order is
+// no longer Pending or a concurrent Route already sent it on.
+func (r *OrderRouter) Route(ctx context.Context, trx *model.Transaction) (*model.TransactionProviderResult, error) {
+	attempts, err := r.repo.GetAttempts(ctx, trx.RefID)
+	if err != nil {
+		return nil, err
+	}
+	if trx.BillRefID != nil {
+		return r.routeBill(ctx, trx, len(attempts) > 0)
+	}
+	tried := make(map[int]bool, len(attempts))
+	for _, a := range attempts {
+		tried[a.ProviderProductID] = true
+	}
+
+	// a fallback must not sell below cost
+	candidates, err := r.repo.GetProviderCandidates(ctx, trx.ProductID, trx.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	last := &model.TransactionProviderResult{
+		Status:  model.TransactionStatusFailed,
+		Message: "No provider available",
+	}
+	// the attempt this decision is based on; StartAttempt refuses to go on if
+	// a concurrent Route has moved the order past it
+	var current *string
+	if len(attempts) > 0 {
+		current = &attempts[len(attempts)-1].ProviderRefID
+	}
+	seq := len(attempts)
+	for _, cand := range candidates {
+		if tried[cand.ProviderProductID] {
+			continue
+		}
+		p, ok := r.providers.Get(cand.ProviderSlug)
+		if !ok {
+			log.Printf("Provider %s is not registered, skipping %s", cand.ProviderSlug, cand.ProviderCode)
+			continue
+		}
+		// an unknown balance does not block the attempt; the provider rejects
+		// the order itself if the deposit is short
+		if err := r.balances.EnsureSufficient(ctx, cand.ProviderSlug, cand.CostPrice); errors.Is(err, ErrSupplierBalanceLow) {
+			log.Printf("Skipping %s via %s: balance below cost %d", cand.ProviderCode, cand.ProviderSlug, cand.CostPrice)
+			continue
+		} else if err != nil {
+			log.Printf("Failed to check %s balance for %s: %v", cand.ProviderSlug, cand.ProviderCode, err)
+		}
+
+		seq++
+		attempt := &model.TransactionAttempt{
+			RefID:             trx.RefID,
+			ProviderRefID:     fmt.Sprintf("%s-%d", trx.RefID, seq),
+			ProviderSlug:      cand.ProviderSlug,
+			ProviderProductID: cand.ProviderProductID,
+			ProviderCode:      cand.ProviderCode,
+			CostPrice:         cand.CostPrice,
+		}
+		started, err := r.repo.StartAttempt(ctx, attempt, current)
+		if err != nil {
+			return nil, err
+		}
+		if !started {
+			return nil, nil
+		}
+		current = &attempt.ProviderRefID
+
+		result := r.purchase(ctx, p, provider.PurchaseRequest{
+			SKU:        cand.ProviderCode,
+			CustomerNo: trx.CustomerNo,
+			RefID:      attempt.ProviderRefID,
+		})
+		if err := r.repo.UpdateAttempt(ctx, attempt.ProviderRefID, result.Status, result.RC, result.Message); err != nil {
+			log.Printf("Failed to store attempt %s: %v", attempt.ProviderRefID, err)
+		}
+		r.balances.Record(ctx, cand.ProviderSlug, result.BuyerLastSaldo, model.SupplierBalanceSourcePurchase, attempt.ProviderRefID)
+
+		last = &model.TransactionProviderResult{
+			Status:         result.Status,
+			RC:             result.RC,
+			SN:             result.SN,
+			Message:        result.Message,
+			BuyerLastSaldo: result.BuyerLastSaldo,
+		}
+		if result.Status != model.TransactionStatusFailed {
+			return last, nil
+		}
+		log.Printf("Attempt %s via %s failed (%s), trying next provider", attempt.ProviderRefID, cand.ProviderSlug, result.Message)
+	}
+
+	return last, nil
+}
+
+// routeBill pays a postpaid bill at the provider it was inquired at, under the
+// inquiry ref. A bill cannot fail over, so an order that already made its one
+// attempt is failed.
+func (r *OrderRouter) routeBill(ctx context.Context, trx *model.Transaction, attempted bool) (*model.TransactionProviderResult, error) {
+	failed := &model.TransactionProviderResult{
+		Status:  model.TransactionStatusFailed,
+		Message: "Bill payment failed",
+	}
+	if attempted {
+		return failed, nil
+	}
+	p, ok := r.providers.Get(trx.ProviderSlug)
+	if !ok {
+		log.Printf("Provider %s is not registered, cannot pay bill %s", trx.ProviderSlug, *trx.BillRefID)
+		return failed, nil
+	}
+
+	attempt := &model.TransactionAttempt{
+		RefID:             trx.RefID,
+		ProviderRefID:     *trx.BillRefID,
+		ProviderSlug:      trx.ProviderSlug,
+		ProviderProductID: trx.ProviderProductID,
+		ProviderCode:      trx.ProviderCode,
+		CostPrice:         trx.CostPrice,
+	}
+	started, err := r.repo.StartAttempt(ctx, attempt, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, nil
+	}
+
+	result := r.purchase(ctx, p, provider.PurchaseRequest{
+		SKU:        trx.ProviderCode,
+		CustomerNo: trx.CustomerNo,
+		RefID:      attempt.ProviderRefID,
+		Postpaid:   true,
+	})
+	if err := r.repo.UpdateAttempt(ctx, attempt.ProviderRefID, result.Status, result.RC, result.Message); err != nil {
+		log.Printf("Failed to store attempt %s: %v", attempt.ProviderRefID, err)
+	}
+	r.balances.Record(ctx, trx.ProviderSlug, result.BuyerLastSaldo, model.SupplierBalanceSourcePurchase, attempt.ProviderRefID)
+
+	return &model.TransactionProviderResult{
+		Status:         result.Status,
+		RC:             result.RC,
+		SN:             result.SN,
+		Message:        result.Message,
+		BuyerLastSaldo: result.BuyerLastSaldo,
+	}, nil
+}
+
+// CheckStatus asks the provider of the order's current attempt where that
+// purchase stands.
+func (r *OrderRouter) CheckStatus(ctx context.Context, trx *model.Transaction) (*provider.PurchaseResult, error) {
+	if trx.ProviderRefID == nil {
+		return nil, fmt.Errorf("transaction %s was never sent to a provider", trx.RefID)
+	}
+	p, ok := r.providers.Get(trx.ProviderSlug)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, trx.ProviderSlug)
+	}
+
+	result, err := r.call(ctx, p.CheckStatus, provider.PurchaseRequest{
+		SKU:        trx.ProviderCode,
+		CustomerNo: trx.CustomerNo,
+		RefID:      *trx.ProviderRefID,
+		Postpaid:   trx.BillRefID != nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.balances.Record(ctx, trx.ProviderSlug, result.BuyerLastSaldo, model.SupplierBalanceSourcePurchase, *trx.ProviderRefID)
+	return result, nil
+}
+
+// Inquiry fetches a postpaid bill from the provider within the router timeout.
+func (r *OrderRouter) Inquiry(ctx context.Context, slug string, req provider.PurchaseRequest) (*provider.Bill, error) {
+	p, ok := r.providers.Get(slug)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, slug)
+	}
+	postpaid, ok := p.(provider.PostpaidProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not support postpaid bills", ErrUnknownProvider, slug)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	req.Postpaid = true
+	return postpaid.Inquiry(callCtx, req)
+}
+
+// purchase places one purchase within the router timeout. When the call errors
+// or times out the provider is asked for the purchase status once; if that
+// also gives no usable answer the attempt stays Pending, since the provider
+// may still fulfil it, and is left to the status poller instead of failing
+// over to another provider.
+func (r *OrderRouter) purchase(ctx context.Context, p provider.Provider, req provider.PurchaseRequest) *provider.PurchaseResult {
+	result, err := r.call(ctx, p.Purchase, req)
+	if err == nil {
+		return result
+	}
+	log.Printf("Purchase %s via %s error: %v, checking status", req.RefID, p.Slug(), err)
+
+	result, statusErr := r.call(ctx, p.CheckStatus, req)
+	if statusErr == nil {
+		return result
+	}
+	log.Printf("Status check %s via %s error: %v", req.RefID, p.Slug(), statusErr)
+
+	return &provider.PurchaseResult{
+		RefID:   req.RefID,
+		Status:  model.TransactionStatusPending,
+		Message: fmt.Sprintf("Provider %s did not respond, awaiting status", p.Slug()),
+	}
+}
+
+func (r *OrderRouter) call(
+	ctx context.Context,
+	fn func(context.Context, provider.PurchaseRequest) (*provider.PurchaseResult, error),
+	req provider.PurchaseRequest,
+) (*provider.PurchaseResult, error) {
+	callCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := fn(callCtx, req)
+	if err != nil {
+		return nil, err
+	}
+	switch result.Status {
+	case model.TransactionStatusPending, model.TransactionStatusSuccess, model.TransactionStatusFailed:
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown status %q: %s", result.Status, result.Message)
+	}
+}
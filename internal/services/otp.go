@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/wafi04/otomaxv2/internal/config"
+)
+
+var (
+	ErrOTPUnavailable = errors.New("otp service is unavailable")
+	ErrOTPCooldown    = errors.New("please wait before requesting another code")
+	ErrOTPExpired     = errors.New("code expired or was never requested")
+	ErrOTPInvalid     = errors.New("invalid code")
+	ErrOTPAttempts    = errors.New("too many attempts, request a new code")
+)
+
+// OTPSender delivers a one-time code to a phone number, e.g. over SMS or WhatsApp.
+type OTPSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// LogOTPSender writes codes to the server log instead of sending them. It is
+// meant for local development only.
+type LogOTPSender struct{}
+
+func (LogOTPSender) Send(ctx context.Context, phone, message string) error {
+	log.Printf("OTP to %s: %s", phone, message)
+	return nil
+}
+
+// HTTPOTPSender posts {"phone", "message"} as JSON to an SMS or WhatsApp relay,
+// authenticated with an API key in the X-API-Key header. Any 2xx answer counts
+// as sent.
+type HTTPOTPSender struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewHTTPOTPSender(url, apiKey string, timeout time.Duration) *HTTPOTPSender {
+	return &HTTPOTPSender{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTPOTPSender) Send(ctx context.Context, phone, message string) error {
+	payload, err := json.Marshal(map[string]string{
+		"phone":   phone,
+		"message": message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if h.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", h.apiKey)
+	}
+
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("otp relay answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OTPService issues and checks one-time codes kept in Redis. Only a hash of the
+// code is stored, together with the number of failed attempts.
+type OTPService struct {
+	redis  *redis.Client
+	sender OTPSender
+	cfg    config.OTPConfig
+}
+
+func NewOTPService(client *redis.Client, sender OTPSender, cfg config.OTPConfig) *OTPService {
+	return &OTPService{
+		redis:  client,
+		sender: sender,
+		cfg:    cfg,
+	}
+}
+
+func otpKey(purpose, subject string) string {
+	return fmt.Sprintf("otp:%s:%s", purpose, subject)
+}
+
+func otpCooldownKey(purpose, subject string) string {
+	return fmt.Sprintf("otp:%s:%s:cooldown", purpose, subject)
+}
+
+// Send generates a new code for purpose/subject, replacing any previous one,
+// and delivers it to phone.
+func (s *OTPService) Send(ctx context.Context, purpose, subject, phone string) error {
+	if s.redis == nil {
+		return ErrOTPUnavailable
+	}
+
+	ok, err := s.redis.SetNX(ctx, otpCooldownKey(purpose, subject), 1, s.cfg.ResendCooldown).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOTPCooldown
+	}
+
+	code, err := generateOTP(s.cfg.Length)
+	if err != nil {
+		return err
+	}
+
+	key := otpKey(purpose, subject)
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, "hash", hashOTP(code), "attempts", 0)
+	pipe.Expire(ctx, key, s.cfg.TTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(s.cfg.TTL/time.Minute))
+	return s.sender.Send(ctx, phone, message)
+}
+
+// Verify checks a code. The code is discarded once it matches or once the
+// attempt limit is reached.
+func (s *OTPService) Verify(ctx context.Context, purpose, subject, code string) error {
+	if s.redis == nil {
+		return ErrOTPUnavailable
+	}
+
+	key := otpKey(purpose, subject)
+	stored, err := s.redis.HGet(ctx, key, "hash").Result()
+	if err == redis.Nil {
+		return ErrOTPExpired
+	}
+	if err != nil {
+		return err
+	}
+
+	attempts, err := s.redis.HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil {
+		return err
+	}
+	if attempts > int64(s.cfg.MaxAttempts) {
+		s.redis.Del(ctx, key)
+		return ErrOTPAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashOTP(code))) != 1 {
+		return ErrOTPInvalid
+	}
+
+	return s.redis.Del(ctx, key).Err()
+}
+
+func generateOTP(length int) (string, error) {
+	code := ""
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code += strconv.FormatInt(n.Int64(), 10)
+	}
+	return code, nil
+}
+
+func hashOTP(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+)
+
+type PriceHistoryService struct {
+	repo *repository.PriceHistoryRepository
+}
+
+func NewPriceHistoryService(repo *repository.PriceHistoryRepository) *PriceHistoryService {
+	return &PriceHistoryService{repo: repo}
+}
+
+// GetProductHistory returns the product's price changes over the last days.
+func (s *PriceHistoryService) GetProductHistory(ctx context.Context, productID, days int) ([]model.PriceHistory, error) {
+	return s.repo.GetByProduct(ctx, productID, time.Now().AddDate(0, 0, -days))
+}
+
+func (s *PriceHistoryService) GetAlerts(ctx context.Context, filter model.FilterPriceAlert) ([]model.PriceHistory, int, error) {
+	return s.repo.GetAlerts(ctx, filter)
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/wafi04/otomaxv2/internal/config"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+)
+
+var (
+	ErrInvalidMarkupRule  = errors.New("invalid markup rule")
+	ErrMarkupRuleNotFound = errors.New("markup rule not found")
+)
+
+// PricingRoles lists the roles a product is priced for, in display order.
+var PricingRoles = []model.UserRole{model.RoleMember, model.RolePlatinum, model.RoleAdmin}
+
+type PricingService struct {
+	repo     *repository.MarkupRuleRepository
+	defaults config.PricingConfig
+}
+
+func NewPricingService(repo *repository.MarkupRuleRepository, defaults config.PricingConfig) *PricingService {
+	return &PricingService{
+		repo:     repo,
+		defaults: defaults,
+	}
+}
+
+func (s *PricingService) Create(ctx context.Context, req model.CreateMarkupRule) (*model.MarkupRule, error) {
+	if err := normalizeMarkupRule(&req); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, req)
+}
+
+func (s *PricingService) Update(ctx context.Context, id int, req model.CreateMarkupRule) (*model.MarkupRule, error) {
+	if err := normalizeMarkupRule(&req); err != nil {
+		return nil, err
+	}
+	rule, err := s.repo.Update(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrMarkupRuleNotFound
+	}
+	return rule, nil
+}
+
+func (s *PricingService) Delete(ctx context.Context, id int) error {
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrMarkupRuleNotFound
+	}
+	return nil
+}
+
+func (s *PricingService) GetAll(ctx context.Context) ([]model.MarkupRule, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// NewPriceBook loads the active rules once so a whole sync is priced against
+// the same rule set.
+func (s *PricingService) NewPriceBook(ctx context.Context) (*PriceBook, error) {
+	rules, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceBook{rules: rules, defaults: s.defaults}, nil
+}
+
+// Preview prices a SKU for every role. A draft rule in the request is
+// evaluated alongside the saved ones without being stored.
+func (s *PricingService) Preview(ctx context.Context, req model.PricePreviewRequest) (*model.PricePreview, error) {
+	book, err := s.NewPriceBook(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Rule != nil {
+		draft := *req.Rule
+		if err := normalizeMarkupRule(&draft); err != nil {
+			return nil, err
+		}
+		book.add(model.MarkupRule{
+			Name:        draft.Name,
+			Role:        draft.Role,
+			Provider:    draft.Provider,
+			Category:    draft.Category,
+			Brand:       draft.Brand,
+			Type:        draft.Type,
+			MinCost:     draft.MinCost,
+			MaxCost:     draft.MaxCost,
+			MarkupType:  draft.MarkupType,
+			MarkupValue: draft.MarkupValue,
+			MinProfit:   draft.MinProfit,
+			Rounding:    draft.Rounding,
+			Priority:    draft.Priority,
+			IsActive:    true,
+		})
+	}
+
+	preview := &model.PricePreview{CostPrice: req.CostPrice}
+	for _, role := range PricingRoles {
+		price, rule := book.Price(req.PricingInput, role)
+		rp := model.RolePrice{Role: role, Price: price, Profit: price - req.CostPrice}
+		if rule != nil {
+			rp.RuleName = rule.Name
+			if rule.ID != 0 {
+				rp.RuleID = &rule.ID
+			}
+		}
+		preview.Prices = append(preview.Prices, rp)
+	}
+	return preview, nil
+}
+
+func normalizeMarkupRule(req *model.CreateMarkupRule) error {
+	req.Role = model.UserRole(strings.ToUpper(string(req.Role)))
+	switch req.Role {
+	case "":
+		req.Role = model.RoleMember
+	case model.RoleMember, model.RolePlatinum, model.RoleAdmin:
+	default:
+		return fmt.Errorf("%w: unknown role %s", ErrInvalidMarkupRule, req.Role)
+	}
+
+	req.MarkupType = strings.ToUpper(req.MarkupType)
+	if req.MarkupType != model.MarkupTypeFixed && req.MarkupType != model.MarkupTypePercentage {
+		return fmt.Errorf("%w: markupType must be FIXED or PERCENTAGE", ErrInvalidMarkupRule)
+	}
+	if req.Rounding != 0 && req.Rounding != 100 && req.Rounding != 500 {
+		return fmt.Errorf("%w: rounding must be 0, 100 or 500", ErrInvalidMarkupRule)
+	}
+	if req.MinCost != nil && req.MaxCost != nil && *req.MinCost > *req.MaxCost {
+		return fmt.Errorf("%w: minCost is greater than maxCost", ErrInvalidMarkupRule)
+	}
+	return nil
+}
+
+// PriceBook evaluates a fixed set of markup rules.
+type PriceBook struct {
+	rules    []model.MarkupRule
+	defaults config.PricingConfig
+}
+
+func (b *PriceBook) add(rule model.MarkupRule) {
+	b.rules = append(b.rules, rule)
+	sort.SliceStable(b.rules, func(i, j int) bool {
+		return b.rules[i].Priority > b.rules[j].Priority
+	})
+}
+
+// Price returns the selling price of a SKU for a role and the rule that set
+// it. Without a matching rule the role's default markup percentage applies.
+func (b *PriceBook) Price(input model.PricingInput, role model.UserRole) (int, *model.MarkupRule) {
+	for i := range b.rules {
+		rule := &b.rules[i]
+		if rule.Role == role && ruleMatches(rule, input) {
+			return applyMarkup(rule, input.CostPrice), rule
+		}
+	}
+	return int(math.Round(float64(input.CostPrice) * float64(100+b.defaultMarkup(role)) / 100)), nil
+}
+
+func (b *PriceBook) defaultMarkup(role model.UserRole) int {
+	switch role {
+	case model.RolePlatinum:
+		return b.defaults.PlatinumMarkup
+	case model.RoleAdmin:
+		return b.defaults.AdminMarkup
+	default:
+		return b.defaults.MemberMarkup
+	}
+}
+
+func ruleMatches(rule *model.MarkupRule, input model.PricingInput) bool {
+	return matchField(rule.Provider, input.Provider) &&
+		matchField(rule.Category, input.Category) &&
+		matchField(rule.Brand, input.Brand) &&
+		matchField(rule.Type, input.Type) &&
+		(rule.MinCost == nil || input.CostPrice >= *rule.MinCost) &&
+		(rule.MaxCost == nil || input.CostPrice <= *rule.MaxCost)
+}
+
+func matchField(want *string, got string) bool {
+	return want == nil || *want == "" || strings.EqualFold(strings.TrimSpace(*want), strings.TrimSpace(got))
+}
+
+// applyMarkup adds the markup, enforces the minimum profit and then rounds up,
+// so rounding never eats into the floor.
+func applyMarkup(rule *model.MarkupRule, cost int) int {
+	var price int
+	if rule.MarkupType == model.MarkupTypePercentage {
+		price = int(math.Ceil(float64(cost) * (100 + rule.MarkupValue) / 100))
+	} else {
+		price = cost + int(math.Ceil(rule.MarkupValue))
+	}
+
+	if price < cost+rule.MinProfit {
+		price = cost + rule.MinProfit
+	}
+	if rule.Rounding > 0 {
+		price = (price + rule.Rounding - 1) / rule.Rounding * rule.Rounding
+	}
+	return price
+}
@@ -19,6 +19,6 @@ func NewProductService(productRepo *repository.ProductRepository) *ProductServic
 
 
 
-func (r *ProductService) GetAll(c *gin.Context)([]*model.ProductData, error){
-	return r.productRepo.GetProducts(c,10,1)
+func (r *ProductService) GetAll(c *gin.Context, role model.UserRole)([]*model.ProductData, error){
+	return r.productRepo.GetProducts(c, role, 10, 1)
 }
\ No newline at end of file
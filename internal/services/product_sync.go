@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+
+	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/integrations/provider"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/internal/services/productexternal"
+)
+
+var ErrSyncRunning = errors.New("a product sync is already running")
+
+// ProductSyncService pulls a provider's price list into the catalog. A
+// Postgres advisory lock, held for the whole run, keeps a single run going
+// across all instances.
+type ProductSyncService struct {
+	provider        provider.Provider
+	productExternal *productexternal.ProductExternal
+	pricing         *PricingService
+	runs            *repository.SyncRunRepository
+	alertPercent    float64
+}
+
+func NewProductSyncService(
+	p provider.Provider,
+	productExternal *productexternal.ProductExternal,
+	pricing *PricingService,
+	runs *repository.SyncRunRepository,
+	alertPercent float64,
+) *ProductSyncService {
+	return &ProductSyncService{
+		provider:        p,
+		productExternal: productExternal,
+		pricing:         pricing,
+		runs:            runs,
+		alertPercent:    alertPercent,
+	}
+}
+
+// Run syncs and waits for the result. It returns ErrSyncRunning when another
+// run holds the lock.
+func (s *ProductSyncService) Run(ctx context.Context, trigger string) (*model.SyncRun, error) {
+	run, unlock, err := s.begin(ctx, trigger)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.execute(ctx, run)
+}
+
+// Start takes the lock and records the run, then syncs in the background.
+func (s *ProductSyncService) Start(ctx context.Context, trigger string) (*model.SyncRun, error) {
+	run, unlock, err := s.begin(ctx, trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer unlock()
+		if _, err := s.execute(context.Background(), run); err != nil {
+			log.Printf("Product sync run %d failed: %v", run.ID, err)
+		}
+	}()
+	return run, nil
+}
+
+func (s *ProductSyncService) GetRuns(ctx context.Context, limit, offset int) ([]model.SyncRun, int, error) {
+	return s.runs.GetAll(ctx, limit, offset)
+}
+
+func (s *ProductSyncService) begin(ctx context.Context, trigger string) (*model.SyncRun, func(), error) {
+	unlock, ok, err := s.runs.TryLock(ctx, s.provider.Slug())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrSyncRunning
+	}
+
+	run, err := s.runs.Start(ctx, s.provider.Slug(), trigger)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return run, unlock, nil
+}
+
+func (s *ProductSyncService) execute(ctx context.Context, run *model.SyncRun) (*model.SyncRun, error) {
+	report, syncErr := s.sync(ctx, run.ID)
+
+	errMessage := ""
+	if syncErr != nil {
+		errMessage = syncErr.Error()
+	}
+	finished, err := s.runs.Finish(ctx, run.ID, report, errMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Product sync run %d %s: %d inserted, %d updated, %d skipped, %d failed, %d removed",
+		finished.ID, finished.Status, report.Inserted, report.Updated, report.Skipped, report.Failed, report.Removed)
+	return finished, syncErr
+}
+
+func (s *ProductSyncService) sync(ctx context.Context, runID int) (model.SyncReport, error) {
+	products, err := s.provider.PriceList(ctx)
+	if err != nil {
+		return model.SyncReport{}, err
+	}
+
+	priceBook, err := s.pricing.NewPriceBook(ctx)
+	if err != nil {
+		return model.SyncReport{}, err
+	}
+
+	mapped := make([]*digiflazz.InternalProduct, 0, len(products))
+	for _, p := range products {
+		mapped = append(mapped, s.toInternalProduct(p, priceBook))
+	}
+	return s.productExternal.SyncProducts(ctx, productexternal.SyncParams{
+		Provider:     s.provider.Slug(),
+		RunID:        runID,
+		AlertPercent: s.alertPercent,
+	}, mapped)
+}
+
+func (s *ProductSyncService) toInternalProduct(p provider.Product, priceBook *PriceBook) *digiflazz.InternalProduct {
+	internal := &digiflazz.InternalProduct{
+		ProviderCode: p.Code,
+		ProviderName: p.Name,
+		Category:     p.Category,
+		Brand:        p.Brand,
+		Type:         p.Type,
+		Description:  p.Description,
+		CostPrice:    p.Price,
+		Stock:        p.Stock,
+		IsUnlimited:  p.Unlimited,
+		IsActive:     p.Active,
+		SellerName:   p.SellerName,
+		StartCutOff:  p.StartCutOff,
+		EndCutOff:    p.EndCutOff,
+		SupportMulti: p.Multi,
+		IsPostpaid:   p.Postpaid,
+		Provider:     s.provider.Slug(),
+	}
+
+	pricingInput := model.PricingInput{
+		Provider:  internal.Provider,
+		Category:  internal.Category,
+		Brand:     internal.Brand,
+		Type:      internal.Type,
+		CostPrice: internal.CostPrice,
+	}
+	internal.PriceMember, _ = priceBook.Price(pricingInput, model.RoleMember)
+	internal.PricePlatinum, _ = priceBook.Price(pricingInput, model.RolePlatinum)
+	internal.PriceAdmin, _ = priceBook.Price(pricingInput, model.RoleAdmin)
+	internal.SellingPrice = internal.PriceMember
+	internal.ProfitMargin = profitMargin(internal.CostPrice, internal.SellingPrice)
+	internal.Status = productStatus(p)
+	return internal
+}
+
+func profitMargin(costPrice, sellingPrice int) int {
+	if costPrice == 0 {
+		return 0
+	}
+	return int(math.Round(float64(sellingPrice-costPrice) * 100 / float64(costPrice)))
+}
+
+func productStatus(p provider.Product) string {
+	if !p.Active {
+		return "inactive"
+	}
+	if p.Stock == 0 && !p.Unlimited {
+		return "out_of_stock"
+	}
+	return "active"
+}
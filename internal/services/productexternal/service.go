@@ -7,8 +7,12 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/lib/pq"
 	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/model"
 )
 
 type ProductExternal struct {
@@ -23,266 +27,436 @@ func NewProductExternal(digiService *digiflazz.DigiflazzService, db *sql.DB) *Pr
 	}
 }
 
-func (repo *ProductExternal) getCategoryAndSubCategory(ctx context.Context, categoryName, brand string) (int, int, error) {
-	var categoryID sql.NullInt64
+// defaultSubCategoryID is assigned to every synced product until sub categories
+// are mapped from the price list.
+const defaultSubCategoryID = 1
+
+const maxInt32 = 2147483647
+
+var denominationPattern = regexp.MustCompile(`\d+`)
+
+// lowestRolePrice is the cheapest price a staged SKU is sold at. A cost above
+// it means some role buys the product at a loss.
+const lowestRolePrice = `LEAST(s.price_member, s.price_platinum, s.price_admin)`
+
+// productStatus derives a product's status from all of its provider SKUs: it
+// stays active while any of them can fulfil it.
+const productStatus = `CASE WHEN EXISTS (
+		SELECT 1 FROM provider_products pp
+		WHERE pp.product_id = p.id
+		  AND pp.is_available = true
+		  AND pp.is_maintenance = false
+		  AND pp.status = 'active'
+	) THEN 'active' ELSE 'inactive' END`
+
+// SyncParams identifies the sync run applying a price list. Cost price moves
+// larger than AlertPercent since the previous sync are flagged for review.
+type SyncParams struct {
+	Provider     string
+	RunID        int
+	AlertPercent float64
+}
+
+// stagingColumns are copied into the sync_staging temp table, one row per SKU.
+var stagingColumns = []string{
+	"code", "name", "category_id", "sub_category_id", "description",
+	"cost_price", "selling_price", "profit_margin", "price_member", "price_platinum", "price_admin",
+	"denomination", "denomination_type", "sort_order", "status", "stock", "is_available",
+	"start_cut_off", "end_cut_off", "is_postpaid",
+}
 
-	queryCat := `SELECT id FROM categories WHERE LOWER(brand) = LOWER($1)`
-	err := repo.DB.QueryRowContext(ctx, queryCat, brand).Scan(&categoryID)
+// categoryIndex resolves a price list row to a category, by brand first and
+// then by category name, the same way the old per-row lookup did.
+type categoryIndex struct {
+	byBrand map[string]int
+	byName  map[string]int
+}
+
+func (pe *ProductExternal) loadCategories(ctx context.Context) (*categoryIndex, error) {
+	rows, err := pe.DB.QueryContext(ctx, `SELECT id, LOWER(name), LOWER(COALESCE(brand, '')) FROM categories`)
 	if err != nil {
-		queryCatName := `SELECT id FROM categories WHERE LOWER(name) = LOWER($1)`
-		err = repo.DB.QueryRowContext(ctx, queryCatName, categoryName).Scan(&categoryID)
-		if err != nil {
-			return 0, 0, fmt.Errorf("category not found for brand: %s, category: %s", brand, categoryName)
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := &categoryIndex{byBrand: map[string]int{}, byName: map[string]int{}}
+	for rows.Next() {
+		var id int
+		var name, brand string
+		if err := rows.Scan(&id, &name, &brand); err != nil {
+			return nil, err
 		}
+		if _, ok := index.byBrand[brand]; !ok && brand != "" {
+			index.byBrand[brand] = id
+		}
+		if _, ok := index.byName[name]; !ok {
+			index.byName[name] = id
+		}
+	}
+	return index, rows.Err()
+}
+
+func (c *categoryIndex) find(categoryName, brand string) (int, bool) {
+	if id, ok := c.byBrand[strings.ToLower(brand)]; ok {
+		return id, true
 	}
-	return int(categoryID.Int64), 1, nil
+	id, ok := c.byName[strings.ToLower(categoryName)]
+	return id, ok
 }
 
-func (pe *ProductExternal) GetProductDigiflazz(ctx context.Context, data []*digiflazz.InternalProduct) ([]*digiflazz.InternalProduct, error) {
-	var processedProducts []*digiflazz.InternalProduct
+// SyncProducts applies one provider's full price list. Rows are matched to
+// existing SKUs on provider + provider code, validated in Go, staged with COPY
+// and written with a few bulk upserts inside one SQL transaction. Rows without
+// a known category are skipped; rows that fail validation are reported back.
+// SKUs of the provider that are missing from the list are marked unavailable.
+func (pe *ProductExternal) SyncProducts(ctx context.Context, params SyncParams, data []*digiflazz.InternalProduct) (model.SyncReport, error) {
+	var report model.SyncReport
+
+	// an empty list is far more likely a provider glitch than a delisted catalog
+	if len(data) == 0 {
+		return report, fmt.Errorf("provider %s returned an empty price list", params.Provider)
+	}
+
+	categories, err := pe.loadCategories(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to load categories: %w", err)
+	}
 
+	staged := make([]*digiflazz.InternalProduct, 0, len(data))
+	listed := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(data))
 	for _, product := range data {
-		// Get category and subcategory for each product
-		categoryID, subCategoryID, err := pe.getCategoryAndSubCategory(ctx, product.Category, product.Brand)
-		if err != nil {
-			log.Printf("Warning: Failed to get category for product %s: %v", product.ProviderCode, err)
-			continue
+		if product.ProviderCode != "" && !seen[product.ProviderCode] {
+			listed = append(listed, product.ProviderCode)
 		}
-
-		processedProduct := &digiflazz.InternalProduct{
-			ProviderCode:  product.ProviderCode,
-			ProviderName:  product.ProviderName,
-			Category:      product.Category,
-			Brand:         product.Brand,
-			Type:          product.Type,
-			Description:   product.Description,
-			CostPrice:     product.CostPrice,
-			SellingPrice:  product.SellingPrice,
-			ProfitMargin:  product.ProfitMargin,
-			Stock:         product.Stock,
-			IsUnlimited:   product.IsUnlimited,
-			IsActive:      product.IsActive,
-			Status:        product.Status,
-			SellerName:    product.SellerName,
-			StartCutOff:   product.StartCutOff,
-			EndCutOff:     product.EndCutOff,
-			SupportMulti:  product.SupportMulti,
-			Provider:      product.Provider,
-			CategoryID:    &categoryID,
-			SubCategoryID: &subCategoryID,
+		if reason := pe.validateProduct(product, seen); reason != "" {
+			report.Failures = append(report.Failures, model.SyncFailure{Code: product.ProviderCode, Reason: reason})
+			continue
 		}
+		seen[product.ProviderCode] = true
 
-		// Optional: Save to database
-		err = pe.SaveProducts(ctx, processedProduct)
-		if err != nil {
-			log.Printf("Warning: Failed to save product %s: %v", product.ProviderCode, err)
-			// Continue processing other products
+		categoryID, ok := categories.find(product.Category, product.Brand)
+		if !ok {
+			log.Printf("Warning: No category for product %s (brand: %s, category: %s)", product.ProviderCode, product.Brand, product.Category)
+			report.Skipped++
+			continue
 		}
-
-		processedProducts = append(processedProducts, processedProduct)
+		subCategoryID := defaultSubCategoryID
+		product.CategoryID = &categoryID
+		product.SubCategoryID = &subCategoryID
+		staged = append(staged, product)
 	}
+	report.Failed = len(report.Failures)
 
-	return processedProducts, nil
+	if err := pe.applyBatch(ctx, params, staged, listed, &report); err != nil {
+		return report, err
+	}
+	return report, nil
 }
 
-func (pe *ProductExternal) SaveProducts(ctx context.Context, product *digiflazz.InternalProduct) error {
-	log.Printf("=== PROCESSING PRODUCT: %s ===", product.ProviderCode)
-
-	// Debug overflow check
-	const maxInt32 = 2147483647
-	const minInt32 = -2147483648
-
-	if product.CostPrice > maxInt32 || product.CostPrice < minInt32 {
-		log.Printf("OVERFLOW: CostPrice %s", product.ProviderCode)
-		return fmt.Errorf("cost price overflow for product %s", product.ProviderCode)
+func (pe *ProductExternal) validateProduct(product *digiflazz.InternalProduct, seen map[string]bool) string {
+	switch {
+	case strings.TrimSpace(product.ProviderCode) == "":
+		return "missing provider code"
+	case seen[product.ProviderCode]:
+		return "duplicate provider code in price list"
+	case product.CostPrice < 0 || product.CostPrice > maxInt32:
+		return fmt.Sprintf("cost price %d out of range", product.CostPrice)
+	case product.SellingPrice < 0 || product.SellingPrice > maxInt32:
+		return fmt.Sprintf("selling price %d out of range", product.SellingPrice)
 	}
-	if product.SellingPrice > maxInt32 || product.SellingPrice < minInt32 {
-		log.Printf("OVERFLOW: SellingPrice %s", product.ProviderCode)
-		return fmt.Errorf("selling price overflow for product %s", product.ProviderCode)
+
+	// lengths follow the sync_staging columns; one over-long value would
+	// otherwise fail the COPY for the whole list
+	for _, field := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"provider code", product.ProviderCode, 100},
+		{"name", product.ProviderName, 255},
+		{"status", product.Status, 20},
+		{"denomination", pe.getDenomination(product), 50},
+	} {
+		if n := utf8.RuneCountInString(field.value); n > field.max {
+			return fmt.Sprintf("%s is %d characters, at most %d allowed", field.name, n, field.max)
+		}
 	}
+	return ""
+}
 
-	// Mulai transaction untuk konsistensi data
+// applyBatch writes the staged rows and deactivates the provider's SKUs that
+// are not in listed, filling the counts and removals of report.
+func (pe *ProductExternal) applyBatch(ctx context.Context, params SyncParams, staged []*digiflazz.InternalProduct, listed []string, report *model.SyncReport) error {
 	tx, err := pe.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	// 1. PERTAMA: Save/Update Main Product dan dapatkan product_id
-	productID, err := pe.saveOrUpdateMainProduct(ctx, tx, product)
-	if err != nil {
-		return fmt.Errorf("failed to save main product: %v", err)
+	var providerID int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM providers WHERE slug = $1`, params.Provider).Scan(&providerID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("provider %s is not registered", params.Provider)
 	}
-
-	// 2. KEDUA: Save/Update Provider Product dengan product_id yang didapat
-	err = pe.saveOrUpdateProviderProduct(ctx, tx, product, productID)
 	if err != nil {
-		return fmt.Errorf("failed to save provider product: %v", err)
+		return err
 	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %v", err)
+	if err := pe.stage(ctx, tx, staged); err != nil {
+		return fmt.Errorf("failed to stage price list: %w", err)
 	}
 
-	log.Printf("=== SUCCESS PROCESSING: %s (Product ID: %d) ===", product.ProviderCode, productID)
-	return nil
-}
-
-// UPDATED: saveOrUpdateMainProduct sekarang return product_id
-func (pe *ProductExternal) saveOrUpdateMainProduct(ctx context.Context, tx *sql.Tx, product *digiflazz.InternalProduct) (int, error) {
-	// Check if main product exists
-	var existingProductID int
-	checkMainQuery := `
-		SELECT id FROM products 
-		WHERE name = $1 OR description LIKE $2`
+	// Existing SKUs refresh the product they already belong to. Its status is
+	// recomputed from all of its provider SKUs once they are upserted.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products p
+		SET price = s.selling_price, original_price = s.cost_price, stock = s.stock,
+			price_member = s.price_member, price_platinum = s.price_platinum, price_admin = s.price_admin,
+			updated_at = NOW()
+		FROM sync_staging s
+		JOIN provider_products pp ON pp.provider_id = $1 AND pp.provider_code = s.code
+		WHERE p.id = pp.product_id`, providerID)
+	if err != nil {
+		return fmt.Errorf("failed to update products: %w", err)
+	}
 
-	searchPattern := "%" + product.ProviderCode + "%"
-	err := tx.QueryRowContext(ctx, checkMainQuery, product.ProviderName, searchPattern).Scan(&existingProductID)
+	// New SKUs get a product keyed by the SKU itself
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO products (
+			category_id, sub_category_id, name, description, price, original_price,
+			price_member, price_platinum, price_admin,
+			denomination, denomination_type, sort_order, status, stock,
+			source_provider, source_code, created_at, updated_at
+		)
+		SELECT s.category_id, s.sub_category_id, s.name, s.description, s.selling_price, s.cost_price,
+			s.price_member, s.price_platinum, s.price_admin,
+			s.denomination, s.denomination_type, s.sort_order, s.status, s.stock,
+			$2, s.code, NOW(), NOW()
+		FROM sync_staging s
+		WHERE NOT EXISTS (
+			SELECT 1 FROM provider_products pp WHERE pp.provider_id = $1 AND pp.provider_code = s.code
+		)
+		ON CONFLICT (source_provider, source_code) DO UPDATE
+		SET price = EXCLUDED.price, original_price = EXCLUDED.original_price,
+			status = EXCLUDED.status, stock = EXCLUDED.stock,
+			price_member = EXCLUDED.price_member, price_platinum = EXCLUDED.price_platinum,
+			price_admin = EXCLUDED.price_admin, updated_at = NOW()`, providerID, params.Provider)
+	if err != nil {
+		return fmt.Errorf("failed to insert products: %w", err)
+	}
 
-	if err == sql.ErrNoRows {
-		// Insert new main product dan return ID-nya
-		log.Printf("Inserting new main product for: %s", product.ProviderCode)
+	if err := pe.recordPriceChanges(ctx, tx, providerID, params); err != nil {
+		return fmt.Errorf("failed to record price changes: %w", err)
+	}
 
-		insertMainQuery := `
-			INSERT INTO products (
-				category_id, sub_category_id, name, description, price, original_price,
-				denomination, denomination_type, sort_order, status, stock, 
+	// New SKUs also get their first price_history row so charts start at the first sync
+	err = tx.QueryRowContext(ctx, `
+		WITH upserted AS (
+			INSERT INTO provider_products (
+				provider_id, product_id, provider_code, provider_name, cost_price, selling_price,
+				profit_margin, stock, status, is_available, start_cut_off, end_cut_off, is_postpaid,
 				created_at, updated_at
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
-			RETURNING id`
-
-		var newProductID int
-		err = tx.QueryRowContext(ctx, insertMainQuery,
-			product.CategoryID,
-			product.SubCategoryID,
-			product.ProviderName,
-			fmt.Sprintf("Provider: %s, Code: %s", product.Provider, product.ProviderCode),
-			product.SellingPrice,
-			product.CostPrice,
-			pe.getDenomination(product),
-			pe.getDenominationType(product),
-			pe.getSortOrder(product),
-			product.Status,
-			product.Stock).Scan(&newProductID)
+			)
+			SELECT $1, COALESCE(pp.product_id, p.id), s.code, s.name, s.cost_price, s.selling_price,
+				s.profit_margin, s.stock, s.status, s.is_available, s.start_cut_off, s.end_cut_off, s.is_postpaid,
+				NOW(), NOW()
+			FROM sync_staging s
+			LEFT JOIN provider_products pp ON pp.provider_id = $1 AND pp.provider_code = s.code
+			LEFT JOIN products p ON p.source_provider = $2 AND p.source_code = s.code
+			ON CONFLICT (provider_id, provider_code) DO UPDATE
+			SET provider_name = EXCLUDED.provider_name, cost_price = EXCLUDED.cost_price,
+				selling_price = EXCLUDED.selling_price, profit_margin = EXCLUDED.profit_margin,
+				stock = EXCLUDED.stock, status = EXCLUDED.status, is_available = EXCLUDED.is_available,
+				start_cut_off = EXCLUDED.start_cut_off, end_cut_off = EXCLUDED.end_cut_off,
+				is_postpaid = EXCLUDED.is_postpaid, updated_at = NOW()
+			RETURNING id, product_id, provider_code, cost_price, selling_price, (xmax = 0) AS inserted
+		), initial AS (
+			INSERT INTO price_history (
+				provider_product_id, product_id, sync_run_id, new_cost_price, new_selling_price,
+				alert_type, created_at
+			)
+			SELECT u.id, u.product_id, NULLIF($3, 0), u.cost_price, u.selling_price,
+				CASE WHEN u.cost_price > `+lowestRolePrice+` THEN 'NEGATIVE_MARGIN' END, NOW()
+			FROM upserted u
+			JOIN sync_staging s ON s.code = u.provider_code
+			WHERE u.inserted
+		)
+		SELECT COUNT(*) FILTER (WHERE inserted), COUNT(*) FILTER (WHERE NOT inserted)
+		FROM upserted`, providerID, params.Provider, params.RunID).Scan(&report.Inserted, &report.Updated)
+	if err != nil {
+		return fmt.Errorf("failed to upsert provider products: %w", err)
+	}
 
-		if err != nil {
-			log.Printf("INSERT ERROR main product %s: %v", product.ProviderCode, err)
-			return 0, err
-		}
+	removedProductIDs, err := pe.deactivateMissing(ctx, tx, providerID, listed, report)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate missing products: %w", err)
+	}
 
-		log.Printf("Successfully inserted new product with ID: %d", newProductID)
-		return newProductID, nil
+	// Every product touched by the sync, listed or removed, gets its status
+	// recomputed from all of its provider SKUs
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products p
+		SET status = `+productStatus+`, updated_at = NOW()
+		WHERE p.id = ANY($2) OR p.id IN (
+			SELECT pp.product_id
+			FROM sync_staging s
+			JOIN provider_products pp ON pp.provider_id = $1 AND pp.provider_code = s.code
+		)`, providerID, pq.Array(removedProductIDs))
+	if err != nil {
+		return fmt.Errorf("failed to refresh product status: %w", err)
+	}
 
-	} else if err != nil {
-		return 0, fmt.Errorf("failed to check existing main product: %v", err)
-	} else {
-		// Update existing main product
-		log.Printf("Updating existing main product: %s (ID: %d)", product.ProviderName, existingProductID)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
 
-		updateMainQuery := `
-			UPDATE products 
-			SET price = $1, original_price = $2, status = $3, stock = $4, 
-				updated_at = NOW()
-			WHERE id = $5`
+// recordPriceChanges writes a price_history row for every existing SKU whose
+// cost or selling price differs from the staged one. It must run before the
+// provider_products upsert overwrites the old prices.
+func (pe *ProductExternal) recordPriceChanges(ctx context.Context, tx *sql.Tx, providerID int, params SyncParams) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO price_history (
+			provider_product_id, product_id, sync_run_id, old_cost_price, new_cost_price,
+			old_selling_price, new_selling_price, change_percent, alert_type, created_at
+		)
+		SELECT pp.id, pp.product_id, NULLIF($2, 0), pp.cost_price, s.cost_price,
+			pp.selling_price, s.selling_price,
+			CASE WHEN pp.cost_price > 0
+				THEN ROUND((s.cost_price - pp.cost_price) * 100.0 / pp.cost_price, 2) END,
+			CASE
+				WHEN s.cost_price > `+lowestRolePrice+` THEN 'NEGATIVE_MARGIN'
+				WHEN pp.cost_price > 0 AND ABS(s.cost_price - pp.cost_price) * 100.0 / pp.cost_price > $3
+					THEN 'PRICE_JUMP'
+			END,
+			NOW()
+		FROM sync_staging s
+		JOIN provider_products pp ON pp.provider_id = $1 AND pp.provider_code = s.code
+		WHERE pp.cost_price <> s.cost_price OR pp.selling_price <> s.selling_price`,
+		providerID, params.RunID, params.AlertPercent)
+	return err
+}
 
-		_, err = tx.ExecContext(ctx, updateMainQuery,
-			product.SellingPrice,
-			product.CostPrice,
-			product.Status,
-			product.Stock,
-			existingProductID)
+// deactivateMissing marks the provider's available SKUs that are not in listed
+// as unavailable and returns their parent products, whose status the caller
+// recomputes.
+func (pe *ProductExternal) deactivateMissing(ctx context.Context, tx *sql.Tx, providerID int, listed []string, report *model.SyncReport) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		WITH removed AS (
+			UPDATE provider_products pp
+			SET is_available = false, status = 'inactive', updated_at = NOW()
+			WHERE pp.provider_id = $1
+			  AND pp.is_available = true
+			  AND NOT (pp.provider_code = ANY($2))
+			RETURNING pp.provider_code, pp.product_id
+		)
+		SELECT r.provider_code, r.product_id, p.name
+		FROM removed r
+		JOIN products p ON p.id = r.product_id
+		ORDER BY r.provider_code`, providerID, pq.Array(listed))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		if err != nil {
-			log.Printf("UPDATE ERROR main product %s: %v", product.ProviderCode, err)
-			return 0, err
+	var productIDs []int64
+	for rows.Next() {
+		var removal model.SyncRemoval
+		if err := rows.Scan(&removal.Code, &removal.ProductID, &removal.ProductName); err != nil {
+			return nil, err
 		}
-
-		log.Printf("Successfully updated existing product with ID: %d", existingProductID)
-		return existingProductID, nil
+		report.Removals = append(report.Removals, removal)
+		productIDs = append(productIDs, int64(removal.ProductID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
+	report.Removed = len(report.Removals)
+	return productIDs, nil
 }
 
-// UPDATED: Helper function untuk save/update provider_products dengan product_id
-func (pe *ProductExternal) saveOrUpdateProviderProduct(ctx context.Context, tx *sql.Tx, product *digiflazz.InternalProduct, productID int) error {
-	// Check if provider product exists
-	var existingID int
-	checkQuery := `
-		SELECT id FROM provider_products 
-		WHERE provider_code = $1 AND provider_id = (SELECT id FROM providers WHERE slug = $2)`
+// stage loads the rows into a temp table that is dropped at commit.
+func (pe *ProductExternal) stage(ctx context.Context, tx *sql.Tx, staged []*digiflazz.InternalProduct) error {
+	_, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE sync_staging (
+			code              VARCHAR(100) PRIMARY KEY,
+			name              VARCHAR(255) NOT NULL,
+			category_id       INT          NOT NULL,
+			sub_category_id   INT          NOT NULL,
+			description       TEXT,
+			cost_price        INT          NOT NULL,
+			selling_price     INT          NOT NULL,
+			profit_margin     INT          NOT NULL,
+			price_member      INT          NOT NULL,
+			price_platinum    INT          NOT NULL,
+			price_admin       INT          NOT NULL,
+			denomination      VARCHAR(50),
+			denomination_type VARCHAR(50),
+			sort_order        INT          NOT NULL,
+			status            VARCHAR(20)  NOT NULL,
+			stock             INT          NOT NULL,
+			is_available      BOOLEAN      NOT NULL,
+			start_cut_off     TIME,
+			end_cut_off       TIME,
+			is_postpaid       BOOLEAN      NOT NULL
+		) ON COMMIT DROP`)
+	if err != nil {
+		return err
+	}
 
-	err := tx.QueryRowContext(ctx, checkQuery, product.ProviderCode, product.Provider).Scan(&existingID)
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("sync_staging", stagingColumns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
 
-	if err == sql.ErrNoRows {
-		// Insert new provider product DENGAN product_id
-		log.Printf("Inserting new provider product: %s for product ID: %d", product.ProviderCode, productID)
-
-		insertQuery := `
-			INSERT INTO provider_products 
-			(provider_id, product_id, provider_code, provider_name, cost_price, selling_price, 
-			 profit_margin, stock, status, is_available, created_at, updated_at)
-			VALUES 
-			((SELECT id FROM providers WHERE slug = $1), $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())`
-
-		_, err = tx.ExecContext(ctx, insertQuery,
-			product.Provider,
-			productID, // INI YANG PENTING: product_id dari main products table
+	for _, product := range staged {
+		_, err := stmt.ExecContext(ctx,
 			product.ProviderCode,
 			product.ProviderName,
+			*product.CategoryID,
+			*product.SubCategoryID,
+			fmt.Sprintf("Provider: %s, Code: %s", product.Provider, product.ProviderCode),
 			product.CostPrice,
 			product.SellingPrice,
 			product.ProfitMargin,
-			product.Stock,
+			product.PriceMember,
+			product.PricePlatinum,
+			product.PriceAdmin,
+			pe.getDenomination(product),
+			pe.getDenominationType(product),
+			pe.getSortOrder(product),
 			product.Status,
-			product.IsActive)
-
-		if err != nil {
-			log.Printf("INSERT ERROR provider product %s: %v", product.ProviderCode, err)
-			return err
-		}
-
-		log.Printf("Successfully inserted provider product for product ID: %d", productID)
-
-	} else if err != nil {
-		return fmt.Errorf("failed to check existing provider product: %v", err)
-	} else {
-		// Update existing provider product
-		log.Printf("Updating existing provider product: %s (ID: %d)", product.ProviderCode, existingID)
-
-		updateQuery := `
-			UPDATE provider_products 
-			SET provider_name = $1, cost_price = $2, selling_price = $3, 
-				profit_margin = $4, stock = $5, status = $6, is_available = $7, 
-				product_id = $8, updated_at = NOW()
-			WHERE id = $9`
-
-		_, err = tx.ExecContext(ctx, updateQuery,
-			product.ProviderName,
-			product.CostPrice,
-			product.SellingPrice,
-			product.ProfitMargin,
 			product.Stock,
-			product.Status,
 			product.IsActive,
-			productID, // Update product_id juga
-			existingID)
-
+			cutOffTime(product.StartCutOff),
+			cutOffTime(product.EndCutOff),
+			product.IsPostpaid,
+		)
 		if err != nil {
-			log.Printf("UPDATE ERROR provider product %s: %v", product.ProviderCode, err)
 			return err
 		}
-
-		log.Printf("Successfully updated provider product ID: %d with product ID: %d", existingID, productID)
 	}
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
 
-	return nil
+// cutOffTime normalises a provider "HH:MM" cut-off time. Anything unparseable
+// is stored as NULL, i.e. no cut-off.
+func cutOffTime(value string) interface{} {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return t.Format("15:04")
 }
 
 func (pe *ProductExternal) getDenomination(product *digiflazz.InternalProduct) string {
 	// Extract denomination dari product name
 	// Contoh: "Telkomsel 10000" -> "10000"
-	re := regexp.MustCompile(`\d+`)
-	matches := re.FindAllString(product.ProviderName, -1)
+	matches := denominationPattern.FindAllString(product.ProviderName, -1)
 	if len(matches) > 0 {
 		return matches[0]
 	}
@@ -320,4 +494,4 @@ func (pe *ProductExternal) getSortOrder(product *digiflazz.InternalProduct) int
 	} else {
 		return 3
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/crypto"
+)
+
+var (
+	ErrResellerRole     = errors.New("only platinum members can use the reseller API")
+	ErrResellerNotFound = errors.New("no reseller API key has been issued")
+	ErrResellerSettings = errors.New("invalid reseller settings")
+	ErrResellerAuth     = errors.New("invalid username or signature")
+	ErrResellerIP       = errors.New("ip address is not whitelisted")
+	ErrRefIDRequired    = errors.New("ref_id is required")
+	ErrRefIDConflict    = errors.New("ref_id was already used for a different product or customer number")
+)
+
+// Signature subjects for calls that have no ref_id, as Digiflazz uses them.
+const (
+	ResellerSignPriceList = "pricelist"
+	ResellerSignBalance   = "depo"
+)
+
+// ResellerService runs the host-to-host (H2H) API: key management for
+// resellers and the signed price list, purchase, status and balance calls.
+type ResellerService struct {
+	repo         *repository.ResellerRepository
+	orders       *repository.TransactionRepository
+	transactions *TransactionService
+	wallet       *WalletService
+}
+
+func NewResellerService(
+	repo *repository.ResellerRepository,
+	orders *repository.TransactionRepository,
+	transactions *TransactionService,
+	wallet *WalletService,
+) *ResellerService {
+	return &ResellerService{
+		repo:         repo,
+		orders:       orders,
+		transactions: transactions,
+		wallet:       wallet,
+	}
+}
+
+// IssueKey creates or rotates the user's API key. The key is only ever
+// returned here; the previous key stops working immediately.
+func (s *ResellerService) IssueKey(ctx context.Context, user *model.UserData) (*model.IssuedResellerKey, error) {
+	if user.Role != model.RolePlatinum && user.Role != model.RoleAdmin {
+		return nil, ErrResellerRole
+	}
+
+	apiKey := crypto.GenerateAPIKey()
+	settings, err := s.repo.Issue(ctx, user.ID, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &model.IssuedResellerKey{APIKey: apiKey, Settings: settings}, nil
+}
+
+func (s *ResellerService) GetSettings(ctx context.Context, userID int) (*model.ResellerKey, error) {
+	settings, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, ErrResellerNotFound
+	}
+	return settings, nil
+}
+
+// UpdateSettings replaces the IP whitelist and callback URL. Whitelist entries
+// are single addresses or CIDR ranges, and at least one is required. The
+// callback must reach a public address.
+func (s *ResellerService) UpdateSettings(ctx context.Context, userID int, input model.UpdateResellerSettings) (*model.ResellerKey, error) {
+	if len(input.IPWhitelist) == 0 {
+		return nil, fmt.Errorf("%w: add at least one whitelisted IP address", ErrResellerSettings)
+	}
+	for i, entry := range input.IPWhitelist {
+		entry = strings.TrimSpace(entry)
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrResellerSettings, entry)
+			}
+		}
+		input.IPWhitelist[i] = entry
+	}
+	if input.CallbackURL != nil && *input.CallbackURL != "" {
+		u, err := url.Parse(*input.CallbackURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("%w: callback URL must be an absolute http(s) URL", ErrResellerSettings)
+		}
+		if err := checkPublicHost(ctx, u.Hostname()); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrResellerSettings, err)
+		}
+	}
+
+	settings, err := s.repo.UpdateSettings(ctx, userID, input)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, ErrResellerNotFound
+	}
+	return settings, nil
+}
+
+// SetActive lets admins suspend or restore a reseller's API access.
+func (s *ResellerService) SetActive(ctx context.Context, userID int, active bool) error {
+	updated, err := s.repo.SetActive(ctx, userID, active)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrResellerNotFound
+	}
+	return nil
+}
+
+// Authenticate checks an H2H call: the reseller must be active, call from a
+// whitelisted IP and sign md5(username + api key + subject).
+func (s *ResellerService) Authenticate(ctx context.Context, req model.H2HRequest, subject, ip string) (*model.ResellerKey, error) {
+	key, err := s.repo.GetByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.IsActive {
+		return nil, ErrResellerAuth
+	}
+
+	expected := crypto.Hash(key.Username+key.APIKey+subject, crypto.MD5)
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(req.Sign)), []byte(expected)) != 1 {
+		return nil, ErrResellerAuth
+	}
+	if !ipAllowed(key.IPWhitelist, ip) {
+		return nil, ErrResellerIP
+	}
+	return key, nil
+}
+
+// checkPublicHost refuses callback hosts that resolve to loopback, private,
+// link-local (cloud metadata) or otherwise non-routable addresses.
+func checkPublicHost(ctx context.Context, host string) error {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("callback host %s is not public", host)
+	}
+	addrs := []net.IP{net.ParseIP(host)}
+	if addrs[0] == nil {
+		resolved, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil || len(resolved) == 0 {
+			return fmt.Errorf("callback host %s does not resolve", host)
+		}
+		addrs = addrs[:0]
+		for _, a := range resolved {
+			addrs = append(addrs, a.IP)
+		}
+	}
+	for _, ip := range addrs {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("callback host %s is not public", host)
+		}
+	}
+	return nil
+}
+
+// isPublicIP is false for loopback, private, link-local (cloud metadata) and
+// other non-routable addresses.
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast())
+}
+
+// ipAllowed is false for an empty whitelist, so a key without one cannot be
+// used from anywhere.
+func ipAllowed(whitelist []string, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range whitelist {
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(addr) {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ResellerService) PriceList(ctx context.Context, key *model.ResellerKey) ([]model.H2HProduct, error) {
+	return s.repo.GetPriceList(ctx, key.Role)
+}
+
+func (s *ResellerService) Balance(ctx context.Context, key *model.ResellerKey) (*model.WalletBalance, error) {
+	return s.wallet.GetBalance(ctx, key.Username)
+}
+
+// Purchase places a balance-paid order under the reseller's ref_id. Repeating
+// a ref_id never orders twice; it returns the existing order instead, unless
+// the repeat asks for a different product or customer number.
+func (s *ResellerService) Purchase(ctx context.Context, key *model.ResellerKey, req model.H2HRequest) (*model.H2HTransaction, error) {
+	if req.RefID == "" {
+		return nil, ErrRefIDRequired
+	}
+	if existing, err := s.Status(ctx, key, req.RefID); !errors.Is(err, ErrTransactionNotFound) {
+		return s.replay(existing, req, err)
+	}
+
+	refID := req.RefID
+	trx, err := s.transactions.Create(ctx, model.CreateTransaction{
+		ProductID:     req.ProductID,
+		CustomerNo:    req.CustomerNo,
+		Method:        model.PaymentMethodSaldo,
+		Username:      key.Username,
+		Role:          key.Role,
+		ResellerRefID: &refID,
+		Repeat:        req.Repeat,
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		// a concurrent call with the same ref_id won the insert
+		existing, err := s.Status(ctx, key, req.RefID)
+		return s.replay(existing, req, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := model.NewH2HTransaction(trx)
+	return &out, nil
+}
+
+// replay answers a repeated ref_id with the order it already placed.
+func (s *ResellerService) replay(existing *model.H2HTransaction, req model.H2HRequest, err error) (*model.H2HTransaction, error) {
+	if err != nil {
+		return nil, err
+	}
+	if existing.ProductID != req.ProductID || existing.CustomerNo != strings.TrimSpace(req.CustomerNo) {
+		return nil, ErrRefIDConflict
+	}
+	return existing, nil
+}
+
+func (s *ResellerService) Status(ctx context.Context, key *model.ResellerKey, refID string) (*model.H2HTransaction, error) {
+	if refID == "" {
+		return nil, ErrRefIDRequired
+	}
+	trx, err := s.orders.GetByResellerRefID(ctx, key.Username, refID)
+	if err != nil {
+		return nil, err
+	}
+	if trx == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	out := model.NewH2HTransaction(trx)
+	return &out, nil
+}
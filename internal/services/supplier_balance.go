@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/integrations/provider"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+)
+
+var (
+	// ErrSupplierBalanceLow is shown to customers as is, so it must not leak
+	// anything about our supplier deposit.
+	ErrSupplierBalanceLow = errors.New("this product is temporarily unavailable, please try again later")
+	ErrUnknownProvider    = errors.New("unknown provider")
+)
+
+// SupplierBalanceService tracks our deposit at each provider and guards new
+// orders against running it dry.
+type SupplierBalanceService struct {
+	repo      *repository.SupplierBalanceRepository
+	providers provider.Registry
+	buffer    int
+	maxAge    time.Duration
+}
+
+func NewSupplierBalanceService(repo *repository.SupplierBalanceRepository, providers provider.Registry, buffer int, maxAge time.Duration) *SupplierBalanceService {
+	return &SupplierBalanceService{
+		repo:      repo,
+		providers: providers,
+		buffer:    buffer,
+		maxAge:    maxAge,
+	}
+}
+
+// Refresh asks the provider for its current balance and stores a snapshot.
+func (s *SupplierBalanceService) Refresh(ctx context.Context, slug string) (*model.SupplierBalance, error) {
+	p, ok := s.providers.Get(slug)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, slug)
+	}
+	balance, err := p.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, slug, int64(balance), model.SupplierBalanceSourceCheck, "")
+}
+
+// RefreshAll refreshes every registered provider, logging the ones that fail.
+func (s *SupplierBalanceService) RefreshAll(ctx context.Context) []model.SupplierBalance {
+	var snapshots []model.SupplierBalance
+	for slug := range s.providers {
+		snapshot, err := s.Refresh(ctx, slug)
+		if err != nil {
+			log.Printf("Failed to check %s balance: %v", slug, err)
+			continue
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+	return snapshots
+}
+
+// Record stores the balance a provider reported alongside a purchase. A nil
+// balance means the provider did not report one; an empty deposit is stored.
+func (s *SupplierBalanceService) Record(ctx context.Context, slug string, balance *int, source, reference string) {
+	if balance == nil {
+		return
+	}
+	if _, err := s.repo.Create(ctx, slug, int64(*balance), source, reference); err != nil {
+		log.Printf("Failed to record %s balance from %s: %v", slug, reference, err)
+	}
+}
+
+// EnsureSufficient rejects an order when the provider's last known balance is
+// below its cost plus the configured buffer. A snapshot older than maxAge is
+// refreshed first. If the balance cannot be determined the order is allowed;
+// the provider will reject it if the deposit really is short.
+func (s *SupplierBalanceService) EnsureSufficient(ctx context.Context, slug string, cost int) error {
+	snapshot, err := s.repo.Latest(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if snapshot == nil || time.Since(snapshot.CreatedAt) > s.maxAge {
+		fresh, err := s.Refresh(ctx, slug)
+		if err != nil {
+			log.Printf("Failed to refresh %s balance: %v", slug, err)
+		} else {
+			snapshot = fresh
+		}
+	}
+	if snapshot == nil {
+		return nil
+	}
+
+	if snapshot.Balance < int64(cost+s.buffer) {
+		log.Printf("Refusing order: %s balance %d below cost %d + buffer %d", slug, snapshot.Balance, cost, s.buffer)
+		return ErrSupplierBalanceLow
+	}
+	return nil
+}
+
+func (s *SupplierBalanceService) GetAll(ctx context.Context, filter model.FilterSupplierBalance) ([]model.SupplierBalance, int, error) {
+	return s.repo.GetAll(ctx, filter)
+}
@@ -0,0 +1,559 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/integrations/digiflazz"
+	"github.com/wafi04/otomaxv2/internal/integrations/duitku"
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/utils"
+)
+
+var (
+	ErrProductUnavailable  = errors.New("product not found or currently unavailable")
+	ErrProductCutOff       = errors.New("product is in provider cut-off, try again later")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrPaymentMethod       = errors.New("payment method not available")
+	ErrUsernameRequired    = errors.New("username is required to pay with balance")
+	ErrPostpaidProduct     = errors.New("this product is a bill payment, inquire the bill first")
+	ErrDuplicateOrder      = errors.New("an identical order was placed recently")
+)
+
+// DuplicateOrderError refuses an order identical to a recent one. Repeat is
+// the counter that confirms the order as intentional.
+type DuplicateOrderError struct {
+	Repeat int
+}
+
+func (e *DuplicateOrderError) Error() string {
+	return fmt.Sprintf("%s, send repeat %d to order it again", ErrDuplicateOrder, e.Repeat)
+}
+
+func (e *DuplicateOrderError) Unwrap() error {
+	return ErrDuplicateOrder
+}
+
+// OrderNotifier is told when an order reaches Sukses or Gagal.
+type OrderNotifier interface {
+	OrderFinished(ctx context.Context, refID string)
+}
+
+type TransactionService struct {
+	repo       *repository.TransactionRepository
+	methodRepo *repository.MethodRepository
+	router     *OrderRouter
+	balances   *SupplierBalanceService
+	duitku     *duitku.DuitkuService
+	wallet     *WalletService
+	notifier   OrderNotifier
+	// duplicateWindow applies to categories without their own window.
+	duplicateWindow time.Duration
+	callbackUrl     string
+	returnUrl       string
+}
+
+func NewTransactionService(
+	repo *repository.TransactionRepository,
+	methodRepo *repository.MethodRepository,
+	router *OrderRouter,
+	balances *SupplierBalanceService,
+	duitku *duitku.DuitkuService,
+	wallet *WalletService,
+	notifier OrderNotifier,
+	duplicateWindow time.Duration,
+	callbackUrl, returnUrl string,
+) *TransactionService {
+	return &TransactionService{
+		repo:            repo,
+		methodRepo:      methodRepo,
+		router:          router,
+		balances:        balances,
+		duitku:          duitku,
+		wallet:          wallet,
+		notifier:        notifier,
+		duplicateWindow: duplicateWindow,
+		callbackUrl:     callbackUrl,
+		returnUrl:       returnUrl,
+	}
+}
+
+// Create prices the order and takes payment through Checkout.
+func (s *TransactionService) Create(ctx context.Context, req model.CreateTransaction) (*model.Transaction, error) {
+	product, err := s.repo.GetProductForOrder(ctx, req.ProductID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductUnavailable
+	}
+	if product.Postpaid {
+		return nil, ErrPostpaidProduct
+	}
+	if product.InCutOff {
+		return nil, ErrProductCutOff
+	}
+	if err := s.balances.EnsureSufficient(ctx, product.ProviderSlug, product.CostPrice); err != nil {
+		return nil, err
+	}
+
+	// Game top-ups need the zone appended to the user ID, e.g. 12345678 + 1234
+	customerNo := strings.TrimSpace(req.CustomerNo)
+	if req.ZoneID != nil {
+		customerNo += strings.TrimSpace(*req.ZoneID)
+	}
+	prefix := "TRX"
+	trx := &model.Transaction{
+		RefID:             utils.GenerateUniqeID(&prefix),
+		Username:          req.Username,
+		ProductID:         product.ProductID,
+		ProductName:       product.ProductName,
+		ProviderProductID: product.ProviderProductID,
+		ProviderCode:      product.ProviderCode,
+		ProviderSlug:      product.ProviderSlug,
+		CustomerNo:        customerNo,
+		Price:             product.Price,
+		CostPrice:         product.CostPrice,
+		Total:             product.Price,
+		PaymentMethod:     strings.ToUpper(strings.TrimSpace(req.Method)),
+		PaymentStatus:     model.PaymentStatusUnpaid,
+		Status:            model.TransactionStatusPending,
+		ResellerRefID:     req.ResellerRefID,
+	}
+
+	return s.Checkout(ctx, trx, s.duplicateCheck(ctx, req, product, customerNo))
+}
+
+// duplicateCheck returns a Checkout claim that refuses a second identical
+// order (same buyer, product and customer number) within the category's window
+// unless req.Repeat confirms it. The count runs under an advisory lock in the
+// checkout transaction, so two concurrent orders cannot both pass. Guest
+// orders have no buyer to tell apart and are not checked.
+func (s *TransactionService) duplicateCheck(ctx context.Context, req model.CreateTransaction, product *model.OrderProduct, customerNo string) func(exec repository.DBTX) error {
+	window := s.duplicateWindow
+	if product.DuplicateWindowMinutes != nil {
+		window = time.Duration(*product.DuplicateWindowMinutes) * time.Minute
+	}
+	if window <= 0 || req.Username == "" {
+		return nil
+	}
+
+	return func(exec repository.DBTX) error {
+		if err := s.repo.LockOrderKey(ctx, exec, req.Username, product.ProductID, customerNo); err != nil {
+			return err
+		}
+		count, err := s.repo.CountRecentOrders(ctx, exec, req.Username, product.ProductID, customerNo, time.Now().Add(-window))
+		if err != nil {
+			return err
+		}
+		if count > 0 && req.Repeat <= count {
+			return &DuplicateOrderError{Repeat: count + 1}
+		}
+		return nil
+	}
+}
+
+// Checkout takes payment for a new Pending order. SALDO orders are debited from
+// the wallet and sent to the provider right away; gateway orders wait for the
+// payment callback before they are dispatched. claim, when set, runs in the
+// SQL transaction that stores the order and can veto it.
+func (s *TransactionService) Checkout(ctx context.Context, trx *model.Transaction, claim func(exec repository.DBTX) error) (*model.Transaction, error) {
+	if trx.PaymentMethod == model.PaymentMethodSaldo {
+		if err := s.payWithBalance(ctx, trx, claim); err != nil {
+			return nil, err
+		}
+		s.dispatch(ctx, trx)
+		return s.repo.GetByRefID(ctx, trx.RefID)
+	}
+
+	if err := s.payWithGateway(ctx, trx, claim); err != nil {
+		return nil, err
+	}
+	return trx, nil
+}
+
+// payWithBalance stores the order and debits the wallet in one SQL transaction.
+func (s *TransactionService) payWithBalance(ctx context.Context, trx *model.Transaction, claim func(exec repository.DBTX) error) error {
+	if trx.Username == "" {
+		return ErrUsernameRequired
+	}
+
+	trx.PaymentStatus = model.PaymentStatusPaid
+	return s.wallet.RunInTx(ctx, func(tx *sql.Tx) error {
+		if claim != nil {
+			if err := claim(tx); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.Create(ctx, tx, trx); err != nil {
+			return err
+		}
+		_, err := s.wallet.ApplyTx(ctx, tx, model.WalletMutation{
+			Username:      trx.Username,
+			EntryType:     model.LedgerEntryDebit,
+			Amount:        int64(trx.Total),
+			ReferenceType: model.LedgerReferenceTransaction,
+			ReferenceID:   trx.RefID,
+			Description:   fmt.Sprintf("Purchase %s to %s", trx.ProductName, trx.CustomerNo),
+		})
+		return err
+	})
+}
+
+// payWithGateway opens a Duitku invoice for the order total including the method fee.
+// The order is claimed and stored first and the invoice is opened before that
+// SQL transaction commits, so a rejected order never leaves a payable invoice.
+func (s *TransactionService) payWithGateway(ctx context.Context, trx *model.Transaction, claim func(exec repository.DBTX) error) error {
+	method, err := s.methodRepo.GetByCode(ctx, trx.PaymentMethod)
+	if err == sql.ErrNoRows || (err == nil && method.Status != "active") {
+		return ErrPaymentMethod
+	}
+	if err != nil {
+		return err
+	}
+
+	trx.Fee = calculateFee(method, trx.Price)
+	trx.Total = trx.Price + trx.Fee
+	if (method.MinAmount > 0 && trx.Total < method.MinAmount) || (method.MaxAmount > 0 && trx.Total > method.MaxAmount) {
+		return fmt.Errorf("%w: amount %d outside %d-%d", ErrPaymentMethod, trx.Total, method.MinAmount, method.MaxAmount)
+	}
+
+	return repository.WithTransaction(ctx, s.repo.DB, func(tx *sql.Tx) error {
+		if claim != nil {
+			if err := claim(tx); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.Create(ctx, tx, trx); err != nil {
+			return err
+		}
+
+		invoice, err := s.duitku.CreateTransaction(ctx, &duitku.DuitkuCreateTransactionParams{
+			PaymentAmount:   trx.Total,
+			MerchantOrderId: trx.RefID,
+			ProductDetails:  trx.ProductName,
+			PaymentCode:     trx.PaymentMethod,
+			CallbackUrl:     &s.callbackUrl,
+			ReturnUrl:       &s.returnUrl,
+		})
+		if err != nil {
+			return err
+		}
+		if invoice == nil || invoice.Reference == "" {
+			return fmt.Errorf("duitku did not return a payment reference")
+		}
+
+		trx.PaymentReference = &invoice.Reference
+		trx.PaymentUrl = &invoice.PaymentUrl
+		return s.repo.SetPaymentInvoice(ctx, tx, trx.RefID, invoice.Reference, invoice.PaymentUrl)
+	})
+}
+
+func calculateFee(method *model.MethodData, amount int) int {
+	if method.Fee == nil {
+		return 0
+	}
+	fee := *method.Fee
+	if method.FeeType != nil && *method.FeeType == model.FeeTypePercentage {
+		// round up so the gateway fee is always covered
+		return (amount*fee + 99) / 100
+	}
+	return fee
+}
+
+// dispatch routes a paid order to its next provider and refunds it once every
+// provider has rejected it. It reports whether the order reached a final status.
+func (s *TransactionService) dispatch(ctx context.Context, trx *model.Transaction) bool {
+	result, err := s.router.Route(ctx, trx)
+	if err != nil {
+		// The provider may still have received the order, so keep it Pending
+		log.Printf("Failed to route transaction %s: %v", trx.RefID, err)
+		return false
+	}
+	if result == nil {
+		return false
+	}
+
+	updated, err := s.repo.FinalizeProviderResult(ctx, trx.RefID, *result)
+	if err != nil {
+		log.Printf("Failed to store provider result for %s: %v", trx.RefID, err)
+		return false
+	}
+	if !updated {
+		return false
+	}
+
+	switch result.Status {
+	case model.TransactionStatusFailed:
+		s.refund(ctx, trx)
+		s.notifier.OrderFinished(ctx, trx.RefID)
+		return true
+	case model.TransactionStatusSuccess:
+		s.notifier.OrderFinished(ctx, trx.RefID)
+		return true
+	}
+	return false
+}
+
+// refund returns a paid order's total to the member's balance exactly once.
+func (s *TransactionService) refund(ctx context.Context, trx *model.Transaction) {
+	if trx.Username == "" {
+		log.Printf("Transaction %s failed but has no member to refund", trx.RefID)
+		return
+	}
+
+	err := s.wallet.RunInTx(ctx, func(tx *sql.Tx) error {
+		updated, err := s.repo.UpdatePaymentStatus(ctx, tx, trx.RefID, model.PaymentStatusPaid, model.PaymentStatusRefunded)
+		if err != nil || !updated {
+			return err
+		}
+		_, err = s.wallet.ApplyTx(ctx, tx, model.WalletMutation{
+			Username:      trx.Username,
+			EntryType:     model.LedgerEntryCredit,
+			Amount:        int64(trx.Total),
+			ReferenceType: model.LedgerReferenceRefund,
+			ReferenceID:   trx.RefID,
+			Description:   fmt.Sprintf("Refund %s to %s", trx.ProductName, trx.CustomerNo),
+		})
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to refund transaction %s: %v", trx.RefID, err)
+	}
+}
+
+// HandleDuitkuCallback marks a gateway order as paid and dispatches it, or fails
+// it when the payment did not go through.
+func (s *TransactionService) HandleDuitkuCallback(ctx context.Context, params *duitku.DuitkuCallbackParams) error {
+	if !s.duitku.VerifyCallbackSignature(params) {
+		return ErrInvalidSignature
+	}
+
+	trx, err := s.repo.GetByRefID(ctx, params.MerchantOrderId)
+	if err != nil {
+		return err
+	}
+	if trx == nil {
+		return ErrTransactionNotFound
+	}
+
+	if amount, err := strconv.Atoi(params.Amount); err != nil || amount != trx.Total {
+		return ErrAmountMismatch
+	}
+
+	if params.ResultCode != duitku.CallbackResultSuccess {
+		updated, err := s.repo.UpdatePaymentStatus(ctx, s.repo.DB, trx.RefID, model.PaymentStatusUnpaid, model.PaymentStatusFailed)
+		if err != nil || !updated {
+			return err
+		}
+		updated, err = s.repo.FinalizeProviderResult(ctx, trx.RefID, model.TransactionProviderResult{
+			Status:  model.TransactionStatusFailed,
+			Message: "Payment failed",
+		})
+		if updated {
+			s.notifier.OrderFinished(ctx, trx.RefID)
+		}
+		return err
+	}
+
+	updated, err := s.repo.UpdatePaymentStatus(ctx, s.repo.DB, trx.RefID, model.PaymentStatusUnpaid, model.PaymentStatusPaid)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		log.Printf("Duitku callback for %s ignored, payment already %s", trx.RefID, trx.PaymentStatus)
+		return nil
+	}
+
+	trx.PaymentStatus = model.PaymentStatusPaid
+	s.dispatch(ctx, trx)
+	return nil
+}
+
+// HandleDigiflazzCallback applies a provider callback to the attempt it names.
+// The order follows only its current attempt; a superseded attempt's result is
+// recorded, and a late success of one is finalized or escalated because the
+// customer may have been served twice.
+func (s *TransactionService) HandleDigiflazzCallback(ctx context.Context, payload digiflazz.CallbackPayload) error {
+	attempt, err := s.repo.GetAttemptByProviderRefID(ctx, payload.Data.RefID)
+	if err != nil {
+		return err
+	}
+	if attempt == nil {
+		return ErrTransactionNotFound
+	}
+	trx, err := s.repo.GetByRefID(ctx, attempt.RefID)
+	if err != nil {
+		return err
+	}
+	if trx == nil {
+		return ErrTransactionNotFound
+	}
+
+	s.balances.Record(ctx, attempt.ProviderSlug, payload.Data.BuyerLastSaldo, model.SupplierBalanceSourceCallback, payload.Data.RefID)
+	_, err = s.applyProviderResult(ctx, trx, payload.Data.RefID, model.TransactionProviderResult{
+		Status:         payload.Data.Status,
+		RC:             payload.Data.RC,
+		SN:             payload.Data.SN,
+		Message:        payload.Data.Message,
+		BuyerLastSaldo: payload.Data.BuyerLastSaldo,
+	})
+	return err
+}
+
+// applyProviderResult applies a provider's final answer for one attempt. A
+// failure of the order's current attempt fails over to the next provider;
+// success finalizes the order. Results of superseded attempts go through
+// applySupersededResult. It reports whether the result was final.
+func (s *TransactionService) applyProviderResult(ctx context.Context, trx *model.Transaction, providerRefID string, result model.TransactionProviderResult) (bool, error) {
+	if result.Status != model.TransactionStatusSuccess && result.Status != model.TransactionStatusFailed {
+		return false, nil
+	}
+
+	if err := s.repo.UpdateAttempt(ctx, providerRefID, result.Status, result.RC, result.Message); err != nil {
+		return false, err
+	}
+	if trx.ProviderRefID == nil || *trx.ProviderRefID != providerRefID {
+		return true, s.applySupersededResult(ctx, trx, providerRefID, result)
+	}
+	if trx.Status != model.TransactionStatusPending {
+		log.Printf("Provider result for %s ignored, transaction already %s", trx.RefID, trx.Status)
+		return true, nil
+	}
+
+	if result.Status == model.TransactionStatusFailed {
+		s.dispatch(ctx, trx)
+		return true, nil
+	}
+
+	updated, err := s.repo.FinalizeProviderResult(ctx, trx.RefID, result)
+	if updated {
+		s.notifier.OrderFinished(ctx, trx.RefID)
+	}
+	return true, err
+}
+
+// applySupersededResult handles an attempt the order already moved on from. A
+// late failure changes nothing. A late success means the customer was served:
+// a still Pending order is finalized with it, and the order is escalated since
+// the current attempt may deliver as well, or the order was already refunded.
+func (s *TransactionService) applySupersededResult(ctx context.Context, trx *model.Transaction, providerRefID string, result model.TransactionProviderResult) error {
+	if result.Status != model.TransactionStatusSuccess {
+		return nil
+	}
+
+	if trx.Status == model.TransactionStatusPending {
+		updated, err := s.repo.FinalizeProviderResult(ctx, trx.RefID, result)
+		if err != nil {
+			return err
+		}
+		if updated {
+			s.notifier.OrderFinished(ctx, trx.RefID)
+		}
+	}
+
+	reason := fmt.Sprintf("superseded attempt %s reported Sukses (SN %s) while the order was %s", providerRefID, result.SN, trx.Status)
+	log.Printf("ESCALATION: transaction %s: %s", trx.RefID, reason)
+	return s.repo.EscalateConflict(ctx, trx.RefID, reason)
+}
+
+// StatusPollPolicy controls the polling of orders whose provider callback is
+// late. Orders are first checked After their creation, then with a doubling
+// delay capped at MaxBackoff, and escalated to admins once older than
+// EscalateAfter.
+type StatusPollPolicy struct {
+	After         time.Duration
+	MaxBackoff    time.Duration
+	EscalateAfter time.Duration
+}
+
+// statusPollBatchSize caps how many orders one poll checks.
+const statusPollBatchSize = 100
+
+// PollPending re-queries the provider for stuck orders that are due for a
+// check and applies any final status. Paid orders that never reached a
+// provider are routed again; no purchase was sent for them, so this cannot
+// charge twice. It returns how many orders it resolved.
+func (s *TransactionService) PollPending(ctx context.Context, policy StatusPollPolicy) (int, error) {
+	stuck, err := s.repo.GetStuckPending(ctx, time.Now().Add(-policy.After), statusPollBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for i := range stuck {
+		trx := &stuck[i]
+
+		final := false
+		var err error
+		if trx.ProviderRefID == nil {
+			final = s.dispatch(ctx, trx)
+		} else if result, checkErr := s.router.CheckStatus(ctx, trx); checkErr != nil {
+			err = checkErr
+			log.Printf("Status check for %s failed: %v", trx.RefID, err)
+		} else {
+			final, err = s.applyProviderResult(ctx, trx, *trx.ProviderRefID, model.TransactionProviderResult{
+				Status:         result.Status,
+				RC:             result.RC,
+				SN:             result.SN,
+				Message:        result.Message,
+				BuyerLastSaldo: result.BuyerLastSaldo,
+			})
+			if err != nil {
+				log.Printf("Failed to apply status for %s: %v", trx.RefID, err)
+			}
+		}
+		if final && err == nil {
+			resolved++
+			continue
+		}
+
+		if err := s.repo.ScheduleStatusCheck(ctx, trx.RefID, time.Now().Add(statusCheckBackoff(policy, trx.StatusChecks))); err != nil {
+			log.Printf("Failed to schedule status check for %s: %v", trx.RefID, err)
+		}
+		if policy.EscalateAfter > 0 && time.Since(trx.CreatedAt) > policy.EscalateAfter {
+			escalated, err := s.repo.MarkEscalated(ctx, trx.RefID)
+			if err != nil {
+				log.Printf("Failed to escalate %s: %v", trx.RefID, err)
+			} else if escalated && trx.ProviderRefID == nil {
+				log.Printf("ESCALATION: transaction %s paid but not sent to any provider after %s",
+					trx.RefID, time.Since(trx.CreatedAt).Round(time.Minute))
+			} else if escalated {
+				log.Printf("ESCALATION: transaction %s still Pending at %s after %s",
+					trx.RefID, trx.ProviderSlug, time.Since(trx.CreatedAt).Round(time.Minute))
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// statusCheckBackoff doubles the delay with every check already made.
+func statusCheckBackoff(policy StatusPollPolicy, checks int) time.Duration {
+	delay := policy.After
+	for i := 0; i < checks && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	return delay
+}
+
+func (s *TransactionService) GetEscalated(ctx context.Context, limit, offset int) ([]model.Transaction, int, error) {
+	return s.repo.GetEscalated(ctx, limit, offset)
+}
+
+func (s *TransactionService) GetByRefID(ctx context.Context, refID string) (*model.Transaction, error) {
+	return s.repo.GetByRefID(ctx, refID)
+}
+
+func (s *TransactionService) GetAll(ctx context.Context, filter model.FilterTransaction) ([]model.Transaction, int, error) {
+	return s.repo.GetAll(ctx, filter)
+}
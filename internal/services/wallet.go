@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrWalletNotFound      = errors.New("wallet owner not found")
+	ErrInvalidAmount       = errors.New("amount must be greater than zero")
+)
+
+type WalletService struct {
+	repo *repository.WalletRepository
+}
+
+func NewWalletService(repo *repository.WalletRepository) *WalletService {
+	return &WalletService{
+		repo: repo,
+	}
+}
+
+// RunInTx runs fn in a SQL transaction that wallet mutations can join through ApplyTx.
+func (s *WalletService) RunInTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return repository.WithTransaction(ctx, s.repo.DB, fn)
+}
+
+// ApplyTx locks the wallet row, appends the ledger entry and moves the balance,
+// all inside tx. Applying the same reference twice is a no-op and returns nil entry.
+func (s *WalletService) ApplyTx(ctx context.Context, tx *sql.Tx, m model.WalletMutation) (*model.LedgerEntry, error) {
+	if m.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	userID, balance, err := s.repo.LockBalance(ctx, tx, m.Username)
+	if err == sql.ErrNoRows {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.LedgerEntry{
+		UserID:        userID,
+		EntryType:     m.EntryType,
+		Amount:        m.Amount,
+		BalanceBefore: balance,
+		ReferenceType: m.ReferenceType,
+		ReferenceID:   m.ReferenceID,
+		Description:   m.Description,
+	}
+
+	switch m.EntryType {
+	case model.LedgerEntryCredit:
+		entry.BalanceAfter = balance + m.Amount
+	case model.LedgerEntryDebit:
+		if balance < m.Amount {
+			return nil, ErrInsufficientBalance
+		}
+		entry.BalanceAfter = balance - m.Amount
+	default:
+		return nil, fmt.Errorf("unknown ledger entry type %q", m.EntryType)
+	}
+
+	inserted, err := s.repo.InsertEntry(ctx, tx, entry)
+	if err != nil {
+		return nil, err
+	}
+	if !inserted {
+		return nil, nil
+	}
+
+	if err := s.repo.UpdateBalance(ctx, tx, userID, entry.BalanceAfter); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Apply is ApplyTx in its own transaction.
+func (s *WalletService) Apply(ctx context.Context, m model.WalletMutation) (*model.LedgerEntry, error) {
+	var entry *model.LedgerEntry
+	err := s.RunInTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		entry, err = s.ApplyTx(ctx, tx, m)
+		return err
+	})
+	return entry, err
+}
+
+func (s *WalletService) GetBalance(ctx context.Context, username string) (*model.WalletBalance, error) {
+	wb, err := s.repo.GetBalance(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if wb == nil {
+		return nil, ErrWalletNotFound
+	}
+	return wb, nil
+}
+
+func (s *WalletService) GetLedger(ctx context.Context, username string, limit, offset int) ([]model.LedgerEntry, int, error) {
+	wb, err := s.GetBalance(ctx, username)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetEntries(ctx, wb.UserID, limit, offset)
+}
+
+func (s *WalletService) Audit(ctx context.Context, username string) (*model.WalletAudit, error) {
+	wb, err := s.GetBalance(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Audit(ctx, wb.UserID)
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/repository"
+	"github.com/wafi04/otomaxv2/pkg/crypto"
+)
+
+var ErrWebhookNotFound = errors.New("webhook delivery not found")
+
+const webhookBatchSize = 50
+
+// WebhookRetryPolicy spaces failed deliveries Backoff, 2*Backoff, ... apart,
+// capped at MaxBackoff, and gives up after MaxAttempts.
+type WebhookRetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// WebhookService delivers finished H2H orders to the reseller's callback URL.
+// The body is signed with HMAC-SHA1 using the reseller's API key and sent in
+// X-Hub-Signature, the same scheme Digiflazz uses for our callbacks. Every
+// attempt is logged and failures are retried by the dispatcher.
+type WebhookService struct {
+	repo      *repository.WebhookRepository
+	resellers *repository.ResellerRepository
+	orders    *repository.TransactionRepository
+	client    *http.Client
+	policy    WebhookRetryPolicy
+}
+
+func NewWebhookService(
+	repo *repository.WebhookRepository,
+	resellers *repository.ResellerRepository,
+	orders *repository.TransactionRepository,
+	timeout time.Duration,
+	policy WebhookRetryPolicy,
+) *WebhookService {
+	return &WebhookService{
+		repo:      repo,
+		resellers: resellers,
+		orders:    orders,
+		client:    newWebhookClient(timeout),
+		policy:    policy,
+	}
+}
+
+// newWebhookClient checks the callback address when it connects, not only when
+// the URL is saved, so DNS rebinding cannot reach internal hosts. Redirects are
+// not followed; a 3xx answer counts as a failed delivery.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("callback address %s is not public", address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// OrderFinished queues the webhook and makes the first attempt in the
+// background; orders placed outside the reseller API are ignored.
+func (s *WebhookService) OrderFinished(ctx context.Context, refID string) {
+	trx, err := s.orders.GetByRefID(ctx, refID)
+	if err != nil || trx == nil || trx.ResellerRefID == nil {
+		return
+	}
+	key, err := s.resellers.GetByUsername(ctx, trx.Username)
+	if err != nil || key == nil || key.CallbackURL == nil {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]model.H2HTransaction{"data": model.NewH2HTransaction(trx)})
+	if err != nil {
+		log.Printf("Failed to encode webhook for %s: %v", refID, err)
+		return
+	}
+	delivery, err := s.repo.Create(ctx, &model.WebhookDelivery{
+		TransactionRefID: trx.RefID,
+		Username:         trx.Username,
+		URL:              *key.CallbackURL,
+		Payload:          string(payload),
+	}, time.Now().Add(s.lease()))
+	if err != nil {
+		log.Printf("Failed to queue webhook for %s: %v", refID, err)
+		return
+	}
+	if delivery == nil {
+		return
+	}
+
+	go s.attempt(context.Background(), delivery, false)
+}
+
+// Dispatch retries the deliveries that are due and returns how many landed.
+func (s *WebhookService) Dispatch(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.ClaimDue(ctx, time.Now().Add(s.lease()), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.attempt(ctx, &deliveries[i], false) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// Resend posts a delivery again right away, whatever its status, and returns
+// it with its attempt history.
+func (s *WebhookService) Resend(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	delivery, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	s.attempt(ctx, delivery, true)
+	return s.GetByID(ctx, id)
+}
+
+func (s *WebhookService) GetByID(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	delivery, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	delivery.History, err = s.repo.GetAttempts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *WebhookService) GetAll(ctx context.Context, filter model.FilterWebhookDelivery) ([]model.WebhookDelivery, int, error) {
+	return s.repo.GetAll(ctx, filter)
+}
+
+// attempt posts the delivery once and records the outcome. A failure is
+// retried after a backoff until the policy's attempts are used up; a failed
+// resend of a delivery that already landed is only logged.
+func (s *WebhookService) attempt(ctx context.Context, delivery *model.WebhookDelivery, manual bool) bool {
+	started := time.Now()
+	statusCode, err := s.send(ctx, delivery)
+	attempt := &model.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		DurationMs: int(time.Since(started).Milliseconds()),
+		Manual:     manual,
+	}
+	if statusCode > 0 {
+		attempt.StatusCode = &statusCode
+	}
+
+	if err != nil {
+		msg := err.Error()
+		attempt.Error = &msg
+		log.Printf("Webhook %d for %s to %s failed: %v", delivery.ID, delivery.TransactionRefID, delivery.URL, err)
+	}
+
+	recordErr := s.repo.RecordAttempt(ctx, attempt, func(current string, attempts int) (string, time.Time) {
+		now := time.Now()
+		switch {
+		case err == nil, current == model.WebhookStatusDelivered:
+			return model.WebhookStatusDelivered, now
+		case attempts >= s.policy.MaxAttempts:
+			return model.WebhookStatusFailed, now
+		default:
+			return model.WebhookStatusPending, now.Add(webhookBackoff(s.policy, attempts))
+		}
+	})
+	if recordErr != nil {
+		log.Printf("Failed to record webhook attempt %d: %v", delivery.ID, recordErr)
+	}
+	return err == nil
+}
+
+// send signs the stored payload with the reseller's current API key, so a
+// rotated key applies to retries too.
+func (s *WebhookService) send(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	key, err := s.resellers.GetByUsername(ctx, delivery.Username)
+	if err != nil {
+		return 0, err
+	}
+	if key == nil {
+		return 0, ErrResellerNotFound
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hub-Signature", "sha1="+crypto.NewCrypto(key.APIKey).GenerateHMAC(delivery.Payload, crypto.SHA1))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("callback returned status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// lease keeps a claimed delivery away from other dispatch runs while it is
+// being sent.
+func (s *WebhookService) lease() time.Duration {
+	return s.client.Timeout + time.Minute
+}
+
+func webhookBackoff(policy WebhookRetryPolicy, attempts int) time.Duration {
+	delay := policy.Backoff
+	for i := 1; i < attempts && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+// DepositReconciler periodically resolves deposits whose Duitku callback never arrived.
+type DepositReconciler struct {
+	depositService *services.DepositService
+	interval       time.Duration
+	olderThan      time.Duration
+	maxBackoff     time.Duration
+}
+
+func NewDepositReconciler(depositService *services.DepositService, interval, olderThan, maxBackoff time.Duration) *DepositReconciler {
+	return &DepositReconciler{
+		depositService: depositService,
+		interval:       interval,
+		olderThan:      olderThan,
+		maxBackoff:     maxBackoff,
+	}
+}
+
+// Start runs until ctx is cancelled.
+func (w *DepositReconciler) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		log.Printf("Deposit reconciler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			settled, err := w.depositService.ReconcilePending(ctx, w.olderThan, w.maxBackoff)
+			if err != nil {
+				log.Printf("Deposit reconciliation failed: %v", err)
+				continue
+			}
+			if settled > 0 {
+				log.Printf("Deposit reconciliation settled %d deposits", settled)
+			}
+		}
+	}
+}
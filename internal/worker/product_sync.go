@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/model"
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+// ProductSyncScheduler runs the product sync on a fixed interval. Instances
+// that find the sync lock taken skip that tick.
+type ProductSyncScheduler struct {
+	syncService *services.ProductSyncService
+	interval    time.Duration
+}
+
+func NewProductSyncScheduler(syncService *services.ProductSyncService, interval time.Duration) *ProductSyncScheduler {
+	return &ProductSyncScheduler{
+		syncService: syncService,
+		interval:    interval,
+	}
+}
+
+// Start runs until ctx is cancelled.
+func (w *ProductSyncScheduler) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		log.Printf("Product sync scheduler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := w.syncService.Run(ctx, model.SyncTriggerSchedule)
+			if errors.Is(err, services.ErrSyncRunning) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Scheduled product sync failed: %v", err)
+			}
+		}
+	}
+}
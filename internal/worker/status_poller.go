@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+// StatusPoller re-queries providers for paid orders whose callback has not
+// arrived, so a lost callback does not leave an order Pending forever.
+type StatusPoller struct {
+	transactionService *services.TransactionService
+	interval           time.Duration
+	policy             services.StatusPollPolicy
+}
+
+func NewStatusPoller(transactionService *services.TransactionService, interval time.Duration, policy services.StatusPollPolicy) *StatusPoller {
+	return &StatusPoller{
+		transactionService: transactionService,
+		interval:           interval,
+		policy:             policy,
+	}
+}
+
+// Start runs until ctx is cancelled.
+func (w *StatusPoller) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		log.Printf("Order status poller disabled")
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			resolved, err := w.transactionService.PollPending(ctx, w.policy)
+			if err != nil {
+				log.Printf("Order status poll failed: %v", err)
+				continue
+			}
+			if resolved > 0 {
+				log.Printf("Order status poll resolved %d transactions", resolved)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+// SupplierBalanceMonitor snapshots every provider's deposit balance on a fixed
+// interval so admins can follow it even when no orders come in.
+type SupplierBalanceMonitor struct {
+	balanceService *services.SupplierBalanceService
+	interval       time.Duration
+}
+
+func NewSupplierBalanceMonitor(balanceService *services.SupplierBalanceService, interval time.Duration) *SupplierBalanceMonitor {
+	return &SupplierBalanceMonitor{
+		balanceService: balanceService,
+		interval:       interval,
+	}
+}
+
+// Start runs until ctx is cancelled.
+func (w *SupplierBalanceMonitor) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		log.Printf("Supplier balance monitor disabled")
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.balanceService.RefreshAll(ctx)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wafi04/otomaxv2/internal/services"
+)
+
+// WebhookDispatcher retries reseller webhooks that have not been delivered.
+type WebhookDispatcher struct {
+	webhookService *services.WebhookService
+	interval       time.Duration
+}
+
+func NewWebhookDispatcher(webhookService *services.WebhookService, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookService: webhookService,
+		interval:       interval,
+	}
+}
+
+// Start runs until ctx is cancelled.
+func (w *WebhookDispatcher) Start(ctx context.Context) {
+	if w.interval <= 0 {
+		log.Printf("Webhook dispatcher disabled")
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			delivered, err := w.webhookService.Dispatch(ctx)
+			if err != nil {
+				log.Printf("Webhook dispatch failed: %v", err)
+				continue
+			}
+			if delivered > 0 {
+				log.Printf("Webhook dispatch delivered %d webhooks", delivered)
+			}
+		}
+	}
+}
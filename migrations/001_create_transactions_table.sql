@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id                  SERIAL PRIMARY KEY,
+    ref_id              VARCHAR(64)  NOT NULL UNIQUE,
+    username            VARCHAR(100) NOT NULL DEFAULT '',
+    product_id          INT          NOT NULL REFERENCES products(id),
+    provider_product_id INT          NOT NULL REFERENCES provider_products(id),
+    provider_code       VARCHAR(100) NOT NULL,
+    customer_no         VARCHAR(100) NOT NULL,
+    price               INT          NOT NULL,
+    cost_price          INT          NOT NULL,
+    status              VARCHAR(20)  NOT NULL DEFAULT 'Pending',
+    rc                  VARCHAR(10),
+    sn                  TEXT,
+    message             TEXT,
+    buyer_last_saldo    BIGINT,
+    created_at          TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_username ON transactions (username);
+CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions (status);
@@ -0,0 +1,4 @@
+ALTER TABLE deposits ADD COLUMN IF NOT EXISTS deposit_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deposits_deposit_id ON deposits (deposit_id);
+CREATE INDEX IF NOT EXISTS idx_deposits_status_created_at ON deposits (status, created_at);
@@ -0,0 +1,19 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS balance BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id             SERIAL PRIMARY KEY,
+    user_id        INT          NOT NULL REFERENCES users(id),
+    entry_type     VARCHAR(10)  NOT NULL CHECK (entry_type IN ('DEBIT', 'CREDIT')),
+    amount         BIGINT       NOT NULL CHECK (amount > 0),
+    balance_before BIGINT       NOT NULL,
+    balance_after  BIGINT       NOT NULL CHECK (balance_after >= 0),
+    reference_type VARCHAR(30)  NOT NULL,
+    reference_id   VARCHAR(64)  NOT NULL,
+    description    TEXT         NOT NULL DEFAULT '',
+    created_at     TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+-- one entry per direction per business event, so retried callbacks cannot double-credit
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_entries_reference
+    ON ledger_entries (reference_type, reference_id, entry_type);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_user_id ON ledger_entries (user_id, id);
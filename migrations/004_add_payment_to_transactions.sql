@@ -0,0 +1,9 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS payment_method    VARCHAR(30) NOT NULL DEFAULT 'SALDO',
+    ADD COLUMN IF NOT EXISTS payment_status    VARCHAR(20) NOT NULL DEFAULT 'UNPAID',
+    ADD COLUMN IF NOT EXISTS payment_reference VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS payment_url       TEXT,
+    ADD COLUMN IF NOT EXISTS fee               INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS total             INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_payment_status ON transactions (payment_status);
@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'MEMBER';
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS price_member   INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS price_platinum INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS price_admin    INT NOT NULL DEFAULT 0;
+
+-- existing rows keep their current price for every tier until the next sync reprices them
+UPDATE products
+SET price_member = price, price_platinum = price, price_admin = price
+WHERE price_member = 0;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS markup_rules (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(100)  NOT NULL,
+    role         VARCHAR(20)   NOT NULL DEFAULT 'MEMBER',
+    provider     VARCHAR(50),
+    category     VARCHAR(100),
+    brand        VARCHAR(100),
+    type         VARCHAR(100),
+    min_cost     INT,
+    max_cost     INT,
+    markup_type  VARCHAR(20)   NOT NULL CHECK (markup_type IN ('FIXED', 'PERCENTAGE')),
+    markup_value NUMERIC(10,2) NOT NULL CHECK (markup_value >= 0),
+    min_profit   INT           NOT NULL DEFAULT 0,
+    rounding     INT           NOT NULL DEFAULT 0,
+    priority     INT           NOT NULL DEFAULT 0,
+    is_active    BOOLEAN       NOT NULL DEFAULT true,
+    created_at   TIMESTAMP     NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_markup_rules_active ON markup_rules (role, priority DESC) WHERE is_active;
@@ -0,0 +1,21 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS first_name        VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_name         VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS phone             VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS avatar_url        TEXT,
+    ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS status            VARCHAR(20) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS google_id         VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (LOWER(email));
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         SERIAL PRIMARY KEY,
+    token_id   VARCHAR(64) NOT NULL UNIQUE,
+    user_id    INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP   NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
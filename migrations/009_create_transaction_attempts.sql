@@ -0,0 +1,38 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS provider_slug   VARCHAR(50) NOT NULL DEFAULT 'digiflazz',
+    ADD COLUMN IF NOT EXISTS provider_ref_id VARCHAR(64);
+
+-- orders placed before routing existed were sent with their own ref_id
+UPDATE transactions SET provider_ref_id = ref_id WHERE provider_ref_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_provider_ref_id ON transactions (provider_ref_id);
+
+-- one row per purchase sent to a provider; an order fails over by adding attempts
+CREATE TABLE IF NOT EXISTS transaction_attempts (
+    id                  SERIAL PRIMARY KEY,
+    ref_id              VARCHAR(64) NOT NULL REFERENCES transactions(ref_id),
+    provider_ref_id     VARCHAR(64) NOT NULL UNIQUE,
+    provider_slug       VARCHAR(50) NOT NULL,
+    provider_product_id INT         NOT NULL,
+    provider_code       VARCHAR(100) NOT NULL,
+    cost_price          INT         NOT NULL,
+    status              VARCHAR(20) NOT NULL DEFAULT 'Pending',
+    rc                  VARCHAR(10),
+    message             TEXT,
+    created_at          TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_attempts_ref_id ON transaction_attempts (ref_id);
+
+-- those orders also get their attempt, which callbacks and status checks
+-- resolve them by
+INSERT INTO transaction_attempts (
+    ref_id, provider_ref_id, provider_slug, provider_product_id, provider_code,
+    cost_price, status, rc, message, created_at, updated_at
+)
+SELECT ref_id, provider_ref_id, provider_slug, provider_product_id, provider_code,
+    cost_price, status, rc, message, created_at, updated_at
+FROM transactions
+WHERE provider_ref_id = ref_id
+ON CONFLICT (provider_ref_id) DO NOTHING;
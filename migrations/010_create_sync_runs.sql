@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id          SERIAL PRIMARY KEY,
+    provider    VARCHAR(50) NOT NULL,
+    trigger     VARCHAR(20) NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    status      VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'success', 'failed')),
+    inserted    INT         NOT NULL DEFAULT 0,
+    updated     INT         NOT NULL DEFAULT 0,
+    skipped     INT         NOT NULL DEFAULT 0,
+    failed      INT         NOT NULL DEFAULT 0,
+    error       TEXT,
+    started_at  TIMESTAMP   NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_runs_started_at ON sync_runs (started_at DESC);
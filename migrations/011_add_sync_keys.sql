@@ -0,0 +1,19 @@
+-- products are keyed by the provider SKU they were first synced from
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS source_provider VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS source_code     VARCHAR(100);
+
+UPDATE products p
+SET source_provider = src.slug, source_code = src.provider_code
+FROM (
+    SELECT DISTINCT ON (pp.product_id) pp.product_id, pr.slug, pp.provider_code
+    FROM provider_products pp
+    JOIN providers pr ON pr.id = pp.provider_id
+    ORDER BY pp.product_id, pp.id
+) src
+WHERE p.id = src.product_id AND p.source_code IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_source ON products (source_provider, source_code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_products_code ON provider_products (provider_id, provider_code);
+
+ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS failures JSONB NOT NULL DEFAULT '[]';
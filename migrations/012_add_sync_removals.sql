@@ -0,0 +1,4 @@
+-- provider SKUs that were missing from a run's price list and got deactivated
+ALTER TABLE sync_runs
+    ADD COLUMN IF NOT EXISTS removed  INT   NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS removals JSONB NOT NULL DEFAULT '[]';
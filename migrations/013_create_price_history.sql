@@ -0,0 +1,17 @@
+-- one row per provider SKU price change seen by a product sync
+CREATE TABLE IF NOT EXISTS price_history (
+    id                  SERIAL PRIMARY KEY,
+    provider_product_id INT          NOT NULL REFERENCES provider_products(id),
+    product_id          INT          NOT NULL REFERENCES products(id),
+    sync_run_id         INT          REFERENCES sync_runs(id),
+    old_cost_price      INT,
+    new_cost_price      INT          NOT NULL,
+    old_selling_price   INT,
+    new_selling_price   INT          NOT NULL,
+    change_percent      NUMERIC(8,2),
+    alert_type          VARCHAR(20)  CHECK (alert_type IN ('NEGATIVE_MARGIN', 'PRICE_JUMP')),
+    created_at          TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product ON price_history (product_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_price_history_alerts ON price_history (created_at DESC) WHERE alert_type IS NOT NULL;
@@ -0,0 +1,4 @@
+-- daily provider cut-off window in Asia/Jakarta time; it may wrap past midnight
+ALTER TABLE provider_products
+    ADD COLUMN IF NOT EXISTS start_cut_off TIME,
+    ADD COLUMN IF NOT EXISTS end_cut_off   TIME;
@@ -0,0 +1,11 @@
+-- point-in-time deposit balance at a supplier, from cek-saldo or a purchase response
+CREATE TABLE IF NOT EXISTS supplier_balances (
+    id            SERIAL PRIMARY KEY,
+    provider_slug VARCHAR(50) NOT NULL,
+    balance       BIGINT      NOT NULL,
+    source        VARCHAR(20) NOT NULL CHECK (source IN ('CHECK', 'PURCHASE', 'CALLBACK')),
+    reference     VARCHAR(64),
+    created_at    TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_balances_provider ON supplier_balances (provider_slug, created_at DESC);
@@ -0,0 +1,9 @@
+-- status polling for orders whose provider callback never arrived
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS status_checks        INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_status_check_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS escalated_at         TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_pending_checks
+    ON transactions (next_status_check_at NULLS FIRST, created_at)
+    WHERE status = 'Pending';
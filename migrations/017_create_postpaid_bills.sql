@@ -0,0 +1,31 @@
+-- postpaid (pascabayar) SKUs are paid after a bill inquiry instead of bought directly
+ALTER TABLE provider_products
+    ADD COLUMN IF NOT EXISTS is_postpaid BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS bill_ref_id VARCHAR(64);
+
+-- one inquired bill; its total is locked until expires_at and paid by one transaction
+CREATE TABLE IF NOT EXISTS postpaid_bills (
+    id                  SERIAL PRIMARY KEY,
+    ref_id              VARCHAR(64)  NOT NULL UNIQUE,
+    username            VARCHAR(100) NOT NULL DEFAULT '',
+    product_id          INT          NOT NULL REFERENCES products(id),
+    provider_product_id INT          NOT NULL REFERENCES provider_products(id),
+    provider_code       VARCHAR(100) NOT NULL,
+    provider_slug       VARCHAR(50)  NOT NULL,
+    customer_no         VARCHAR(100) NOT NULL,
+    customer_name       VARCHAR(255) NOT NULL DEFAULT '',
+    period              VARCHAR(255) NOT NULL DEFAULT '',
+    amount              INT          NOT NULL,
+    admin_fee           INT          NOT NULL,
+    cost_price          INT          NOT NULL,
+    total               INT          NOT NULL,
+    status              VARCHAR(20)  NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'ORDERED')),
+    transaction_ref_id  VARCHAR(64),
+    expires_at          TIMESTAMP    NOT NULL,
+    created_at          TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_postpaid_bills_username ON postpaid_bills (username);
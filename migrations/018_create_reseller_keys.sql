@@ -0,0 +1,19 @@
+-- host-to-host access for resellers; the key is needed in clear to check md5 signatures
+CREATE TABLE IF NOT EXISTS reseller_keys (
+    id           SERIAL PRIMARY KEY,
+    user_id      INT         NOT NULL UNIQUE REFERENCES users(id),
+    api_key      VARCHAR(128) NOT NULL,
+    ip_whitelist TEXT[]      NOT NULL DEFAULT '{}',
+    callback_url TEXT,
+    is_active    BOOLEAN     NOT NULL DEFAULT true,
+    rotated_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    created_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+-- the reseller's own ref_id; a repeated ref_id is answered with the existing order
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS reseller_ref_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reseller_ref_id
+    ON transactions (username, reseller_ref_id) WHERE reseller_ref_id IS NOT NULL;
@@ -0,0 +1,37 @@
+-- outbound order webhooks to resellers; one delivery per order, retried until it lands
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id                 SERIAL PRIMARY KEY,
+    transaction_ref_id VARCHAR(64) NOT NULL UNIQUE,
+    username           VARCHAR(100) NOT NULL,
+    url                TEXT        NOT NULL,
+    payload            TEXT        NOT NULL,
+    status             VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts           INT         NOT NULL DEFAULT 0,
+    last_status_code   INT,
+    last_error         TEXT,
+    next_attempt_at    TIMESTAMP   NOT NULL DEFAULT NOW(),
+    delivered_at       TIMESTAMP,
+    created_at         TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_username
+    ON webhook_deliveries (username, created_at DESC);
+
+-- every POST made for a delivery, automatic or resent by an admin
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id          SERIAL PRIMARY KEY,
+    delivery_id INT         NOT NULL REFERENCES webhook_deliveries(id),
+    status_code INT,
+    error       TEXT,
+    duration_ms INT         NOT NULL DEFAULT 0,
+    manual      BOOLEAN     NOT NULL DEFAULT false,
+    created_at  TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery
+    ON webhook_attempts (delivery_id, created_at);
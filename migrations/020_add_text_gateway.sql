@@ -0,0 +1,11 @@
+-- text command gateway: senders prove themselves with a PIN and order by keyword
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS pin_hash VARCHAR(255);
+
+-- products without a keyword are ordered by their source SKU code
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS keyword VARCHAR(30);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_keyword ON products (keyword) WHERE keyword IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_source_code_upper ON products (UPPER(source_code));
+
@@ -0,0 +1,17 @@
+-- client-supplied Idempotency-Key headers; a repeat replays the stored response
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id              SERIAL PRIMARY KEY,
+    scope           VARCHAR(50)  NOT NULL,
+    owner           VARCHAR(100) NOT NULL,
+    key             VARCHAR(255) NOT NULL,
+    request_hash    VARCHAR(64)  NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'IN_PROGRESS',
+    response_status INT,
+    response_body   TEXT,
+    expires_at      TIMESTAMP    NOT NULL,
+    created_at      TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP    NOT NULL DEFAULT NOW(),
+    UNIQUE (scope, owner, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,7 @@
+-- per-category window for refusing an identical order (same product and customer number);
+-- NULL uses ORDER_DUPLICATE_WINDOW, 0 disables the check
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS duplicate_window_minutes INT;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_duplicate_check
+    ON transactions (product_id, customer_no, created_at DESC);
@@ -0,0 +1,4 @@
+-- why an order was escalated; set for conflicts that stay listed after the order is final,
+-- e.g. a superseded provider attempt that still delivered
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS escalation_reason TEXT;
@@ -0,0 +1,10 @@
+-- an email only counts as proven once Google vouched for it; password sign-ups
+-- stay unverified and are never linked to a Google login automatically
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+UPDATE users
+SET email_verified_at = created_at
+WHERE google_id IS NOT NULL AND password_hash IS NULL AND email_verified_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_google_id ON users (google_id) WHERE google_id IS NOT NULL;
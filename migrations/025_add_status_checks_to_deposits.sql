@@ -0,0 +1,8 @@
+-- backoff for reconciling deposits whose Duitku callback never arrived
+ALTER TABLE deposits
+    ADD COLUMN IF NOT EXISTS status_checks INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_check_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_deposits_pending_checks
+    ON deposits (next_check_at NULLS FIRST, created_at)
+    WHERE status = 'PENDING';
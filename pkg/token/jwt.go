@@ -0,0 +1,99 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token types carried in the "typ" claim so a refresh token can never be used
+// as an access token and vice versa.
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type Claims struct {
+	UserID   int    `json:"uid"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Type     string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// Manager signs and verifies HS256 tokens.
+type Manager struct {
+	secret     []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewManager(secret, issuer string, accessTTL, refreshTTL time.Duration) *Manager {
+	return &Manager{
+		secret:     []byte(secret),
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (m *Manager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+func (m *Manager) RefreshTTL() time.Duration {
+	return m.refreshTTL
+}
+
+// GenerateAccess issues a short-lived access token.
+func (m *Manager) GenerateAccess(userID int, username, role string) (string, error) {
+	return m.sign(userID, username, role, TypeAccess, "", m.accessTTL)
+}
+
+// GenerateRefresh issues a refresh token whose ID is tracked server side so it
+// can be revoked.
+func (m *Manager) GenerateRefresh(userID int, username, role, tokenID string) (string, error) {
+	return m.sign(userID, username, role, TypeRefresh, tokenID, m.refreshTTL)
+}
+
+func (m *Manager) sign(userID int, username, role, tokenType, tokenID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		UserID:   userID,
+		Username: username,
+		Role:     role,
+		Type:     tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    m.issuer,
+			Subject:   username,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+}
+
+// Parse verifies the signature, issuer, expiry and token type.
+func (m *Manager) Parse(tokenString, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("%w: expected %s token", ErrInvalidToken, tokenType)
+	}
+	return claims, nil
+}
@@ -29,6 +29,7 @@ func NewValidator() *Validator {
 	validate.RegisterValidation("game_type", validateGameType)
 	validate.RegisterValidation("provider", validateProvider)
 	validate.RegisterValidation("amount", validateAmount)
+	validate.RegisterValidation("username", validateUsername)
 	
 	return &Validator{validate: validate}
 }
@@ -103,6 +104,14 @@ func validatePassword(fl validator.FieldLevel) bool {
 	return hasUpper && hasLower && hasDigit && hasSpecial
 }
 
+// validateUsername allows lowercase letters, digits and underscores only, so a
+// username can never look like an email address.
+func validateUsername(fl validator.FieldLevel) bool {
+	return usernamePattern.MatchString(fl.Field().String())
+}
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
 func validateOperator(fl validator.FieldLevel) bool {
 	operator := strings.ToLower(fl.Field().String())
 	validOperators := []string{"telkomsel", "indosat", "xl", "axis", "three", "smartfren", "by.u"}
@@ -170,6 +179,8 @@ func getErrorMessage(err validator.FieldError) string {
 		return fmt.Sprintf("%s must be a valid payment provider", err.Field())
 	case "amount":
 		return fmt.Sprintf("%s must be between 1,000 and 10,000,000", err.Field())
+	case "username":
+		return fmt.Sprintf("%s may only contain lowercase letters, digits and underscores", err.Field())
 	default:
 		return fmt.Sprintf("%s is invalid", err.Field())
 	}